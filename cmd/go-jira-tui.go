@@ -1,25 +1,28 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
+	"runtime"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/guppy0130/go-jira-tui/internal/config"
 	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
 	"github.com/guppy0130/go-jira-tui/internal/model"
 	"github.com/guppy0130/go-jira-tui/pkg/cmd/logger"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 const (
 	accentColor = lipgloss.Color("57")
-	// glamourTheme = "dark"
 )
 
-// 	columnKeyStartDate = "start_date" // sprint start date
-// 	columnKeyEndDate   = "end_date"   // sprint end date
 // 	columnKeyIssueKey  = "issue_key"  // issue key
 // 	columnKeySummary   = "summary"    // issue summary
 // 	columnKeyBack      = "back"       // some arbitrary int to go back to the higher level
@@ -31,11 +34,6 @@ const (
 // 	default_view = keywordBoards
 // )
 
-var (
-// border      = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(accentColor)
-// lightBorder = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("250"))
-)
-
 // // the breadcrumb describes how to get back/what path we've taken
 // type breadcrumb struct {
 // 	t     string // one of the keywords*
@@ -94,38 +92,6 @@ var (
 // 	)
 // }
 
-// func getActiveSprintsInBoard(jiraClient jira.Client, boardId int) tea.Cmd {
-// 	return tea.Batch(
-// 		updateStatusBar(keywordSprints),
-// 		func() tea.Msg {
-// 			sprints, _, err := jiraClient.Board.GetAllSprintsWithOptions(boardId, &jira.GetAllSprintsOptions{State: "active"})
-// 			if err != nil {
-// 				panic(err)
-// 			}
-// 			columns := []table.Column{
-// 				table.NewColumn(columnKeyID, "ID", 4),
-// 				table.NewFlexColumn(columnKeyName, "Name", 1).WithFiltered(true),
-// 				table.NewColumn(columnKeyStartDate, "Start Date", 30),
-// 				table.NewColumn(columnKeyEndDate, "End Date", 30),
-// 			}
-// 			rows := []table.Row{}
-// 			for _, sprint := range sprints.Values {
-// 				rows = append(rows, table.NewRow(
-// 					table.RowData{
-// 						columnKeyID:        sprint.ID,
-// 						columnKeyName:      sprint.Name,
-// 						columnKeyStartDate: sprint.StartDate.Local().Format(time.RFC1123),
-// 						columnKeyEndDate:   sprint.EndDate.Local().Format(time.RFC1123),
-// 						columnKeyBack:      sprint.OriginBoardID,
-// 					},
-// 				))
-// 			}
-
-// 			return tableGenerator(columns, rows)
-// 		},
-// 	)
-// }
-
 // func getIssuesInSprint(jiraClient jira.Client, sprintId int) tea.Cmd {
 // 	return tea.Batch(
 // 		updateStatusBar(keywordIssues),
@@ -155,89 +121,6 @@ var (
 // 	)
 // }
 
-// /*
-//  * summary
-//  * desc       | assignee
-//  * comments?  | reporter
-//  */
-
-// func renderGlamourJira(jiraContent string) string {
-// 	content, err := glamour.Render(j2m.JiraToMD(jiraContent), glamourTheme)
-// 	if err != nil {
-// 		panic(err)
-// 	}
-// 	return content
-// }
-
-// func getIssue(jiraClient jira.Client, issueId string, issueKey string) tea.Cmd {
-// 	// serialize the issue into glamour
-// 	return tea.Batch(
-// 		updateStatusBar(issueKey),
-// 		func() tea.Msg {
-// 			issue, _, err := jiraClient.Issue.Get(issueId, nil)
-// 			if err != nil {
-// 				panic(err)
-// 			}
-// 			// handle rendering the summary
-// 			summary, err := glamour.Render(fmt.Sprintf("# %s", issue.Fields.Summary), glamourTheme)
-// 			if err != nil {
-// 				summary = issue.Fields.Summary
-// 			}
-
-// 			// left half is
-// 			// description and comments
-// 			description := renderGlamourJira(issue.Fields.Description)
-
-// 			comments := strings.Builder{}
-// 			for _, comment := range issue.Fields.Comments.Comments {
-// 				s := strings.Builder{}
-// 				// handle author rendering
-// 				s.WriteString(fmt.Sprintf("%s, at %s", comment.Author.DisplayName, comment.Created))
-// 				// and if there's an update, indicate changes
-// 				if comment.Created != comment.Updated {
-// 					s.WriteString(fmt.Sprintf("\n(Last updated by %s at %s)", comment.UpdateAuthor.DisplayName, comment.Updated))
-// 				}
-// 				// write the body of the comment
-// 				s.WriteString("\n")
-// 				s.WriteString(renderGlamourJira(comment.Body))
-// 				comments.WriteString(lightBorder.Render(s.String()))
-// 				comments.WriteString("\n")
-// 			}
-// 			comment_header, err := glamour.Render("## Comments", glamourTheme)
-// 			if err != nil {
-// 				comment_header = "Comments"
-// 			}
-// 			left_half := lipgloss.JoinVertical(
-// 				lipgloss.Top,
-// 				border.Render(description),
-// 				border.Render(fmt.Sprintf("%s%s", comment_header, comments.String())),
-// 			)
-
-// 			// right half is
-// 			// author, assignee
-// 			rhs_content := strings.Builder{}
-// 			details_header, err := glamour.Render("## Details", glamourTheme)
-// 			if err != nil {
-// 				details_header = "Details"
-// 			}
-// 			rhs_content.WriteString(details_header)
-// 			rhs_content.WriteString(fmt.Sprintf("Assignee: %s", issue.Fields.Assignee.DisplayName))
-// 			rhs_content.WriteString("\n")
-// 			rhs_content.WriteString(fmt.Sprintf("Reporter: %s", issue.Fields.Reporter.DisplayName))
-// 			right_half := lipgloss.JoinVertical(
-// 				lipgloss.Top,
-// 				border.Render(rhs_content.String()),
-// 			)
-
-// 			// render the left and right halves
-// 			content := lipgloss.JoinHorizontal(lipgloss.Top, left_half, right_half)
-// 			// add the summary to the top
-// 			content = lipgloss.JoinVertical(lipgloss.Top, summary, content)
-// 			return updateViewport(content)
-// 		},
-// 	)
-// }
-
 // func (m model) Init() tea.Cmd {
 // 	return getBoards(m.jiraData.client)
 // }
@@ -392,25 +275,95 @@ var (
 // 	)
 // }
 
+// oauth logins are approved in a browser, before the app takes over the
+// terminal
+func approveInBrowser(authURL string) {
+	fmt.Println("Approve the login in your browser. If it didn't open, go to:")
+	fmt.Println(authURL)
+	openBrowser(authURL)
+}
+
+func openBrowser(authURL string) {
+	var open *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		open = exec.Command("open", authURL)
+	case "windows":
+		open = exec.Command("rundll32", "url.dll,FileProtocolHandler", authURL)
+	default:
+		open = exec.Command("xdg-open", authURL)
+	}
+	if err := open.Start(); err != nil {
+		slog.Debug("couldn't open a browser", "err", err)
+		return
+	}
+	go open.Wait()
+}
+
 func main() {
-	// handle config
-	config := config.LoadViper()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "login":
+			if err := login(os.Args[2:]); err != nil {
+				fmt.Println("Error logging in:", err)
+				os.Exit(1)
+			}
+			return
+		case "config":
+			if err := configCommand(os.Args[2:]); err != nil {
+				fmt.Println("Error in config:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
-	// generate client
-	jiraData := jira.NewJiraData(config.Email, config.Token, config.Url)
+	config.AddFlags(pflag.CommandLine)
+	pflag.Parse()
+
+	// handle config, making one the first time round
+	conf, err := config.LoadViper(pflag.CommandLine)
+	if errors.Is(err, config.ErrNoConfig) && term.IsTerminal(int(os.Stdin.Fd())) {
+		written, setupErr := setup(pflag.CommandLine)
+		if setupErr != nil {
+			fmt.Println("Error setting up:", setupErr)
+			os.Exit(1)
+		}
+		if !written {
+			fmt.Println("No config written.")
+			return
+		}
+		conf, err = config.LoadViper(pflag.CommandLine)
+	}
+	if err != nil {
+		fmt.Println("Error reading config:", err)
+		os.Exit(1)
+	}
+
+	// generate client; fake:// urls run offline against bundled fixtures
+	jiraData, err := jira.NewBackend(conf.Active(), approveInBrowser)
+	if err != nil {
+		fmt.Println("Error connecting to jira:", err)
+		os.Exit(1)
+	}
 
-	// create the bubble tea model
-	m := model.NewModel(jiraData, config.AccentColor)
+	// create the bubble tea model, with the config's keys
+	keymap.Keys = conf.KeyMap()
+	m := model.NewModel(jiraData, conf)
 
 	// setup logging
-	f, err := tea.LogToFileWith("go-jira-tui.debug.log", "prefix", logger.NewStructuredBubbleTeaLogger(config.LogFormat))
+	f, err := tea.LogToFileWith("go-jira-tui.debug.log", "prefix", logger.NewStructuredBubbleTeaLogger(conf.LogFormat))
 	if err != nil {
 		panic(err)
 	}
 	defer f.Close()
 	slog.Debug("started logger")
-	// run the UI
-	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+	// run the UI, with the config applied again whenever it's saved
+	program := tea.NewProgram(m, tea.WithAltScreen())
+	conf.Watch(pflag.CommandLine, func(conf config.Config, err error) {
+		program.Send(model.ConfigChangedMsg{Config: conf, Err: err})
+	})
+	if _, err := program.Run(); err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
 	}
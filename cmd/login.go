@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// `go-jira-tui login`: ask for a profile's token, check it works, and save
+// it where the profile's token_source says. oauth profiles are approved in
+// a browser instead, and keep their own tokens.
+func login(args []string) error {
+	flags := pflag.NewFlagSet("login", pflag.ExitOnError)
+	config.AddFlags(flags)
+	flags.Parse(args)
+
+	conf, err := config.LoadViper(flags)
+	if err != nil {
+		return err
+	}
+	profile := conf.Active()
+
+	switch profile.Auth.Type {
+	case config.AuthOAuth2:
+		// a fresh login, even if there's one saved. the tokens get saved
+		// along the way.
+		os.Remove(profile.Auth.OAuth2.TokenFile)
+		jiraData, err := jira.NewBackend(profile, approveInBrowser)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Logged into %s as %s.\n", jiraData.Host(), jiraData.Self().DisplayName)
+		return nil
+	case config.AuthSession:
+		return fmt.Errorf("profile %q uses session auth, which doesn't take a token", profile.Name)
+	}
+	if err := profile.TokenSaveable(); err != nil {
+		return err
+	}
+
+	token, err := readToken(fmt.Sprintf("Token for %s (%s): ", profile.Name, profile.Url))
+	if err != nil {
+		return err
+	}
+	// only save a token that works
+	check := profile
+	check.Token, check.TokenSource = token, config.TokenSourceConfig
+	jiraData, err := jira.NewBackend(check, nil)
+	if err != nil {
+		return err
+	}
+	if err := profile.SaveToken(token); err != nil {
+		return err
+	}
+	fmt.Printf("Logged into %s as %s, and saved the token to the %s.\n", jiraData.Host(), jiraData.Self().DisplayName, profile.TokenSource)
+	return nil
+}
+
+// read a line without echoing it, or straight from stdin if it's piped in
+func readToken(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(line) == "" {
+			return "", fmt.Errorf("reading the token: %w", cmp.Or(err, errors.New("it's empty")))
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Print(prompt)
+	raw, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading the token: %w", err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", errors.New("no token given")
+	}
+	return token, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/spf13/pflag"
+)
+
+// walk through making a config the first time round, and say whether one
+// was written. it goes where --config says, or in the usual place.
+func setup(flags *pflag.FlagSet) (bool, error) {
+	path, err := config.FilePath(flags)
+	if err != nil {
+		return false, err
+	}
+	wizard := jira.NewSetupWizard(path, openBrowser, accentColor)
+	final, err := tea.NewProgram(wizard, tea.WithAltScreen()).Run()
+	if err != nil {
+		return false, err
+	}
+	self := final.(jira.SetupWizard).Self()
+	if self == nil {
+		return false, nil
+	}
+	fmt.Printf("Signed in as %s, and wrote the config to %s.\n", self.DisplayName, path)
+	return true, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/spf13/pflag"
+)
+
+// `go-jira-tui config validate`: say everything that's wrong with the
+// config, in every profile, not just the one that'd be used
+func validateConfig(args []string) error {
+	flags := pflag.NewFlagSet("config validate", pflag.ExitOnError)
+	config.AddFlags(flags)
+	flags.Parse(args)
+
+	conf, err := config.Read(flags)
+	if err != nil {
+		return err
+	}
+	if problems := conf.Validate(); len(problems) > 0 {
+		return problems
+	}
+	fmt.Printf("The config's fine. Its profiles are %s, and %s is used.\n", strings.Join(conf.ProfileNames(), ", "), conf.Profile)
+	return nil
+}
+
+// `go-jira-tui config <command>`
+func configCommand(args []string) error {
+	if len(args) == 0 || args[0] != "validate" {
+		return errors.New("usage: go-jira-tui config validate [flags]")
+	}
+	return validateConfig(args[1:])
+}
@@ -1,50 +1,411 @@
 package config
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fsnotify/fsnotify"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
 	"github.com/guppy0130/go-jira-tui/pkg/cmd/logger"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// environment variables are the config's keys, uppercased with _ for . and
+// this in front, e.g. JIRA_TUI_URL or JIRA_TUI_AUTH_TYPE
+const EnvPrefix = "JIRA_TUI"
+
 // Config for the app
 type Config struct {
-	Email       string              `mapstructurue:"email"`      // your email to sign into atlassian
-	Token       string              `mapstructure:"token"`       // a token for basic auth (tested only with cloud)
-	Url         string              `mapstructure:"url"`         // root URL; e.g., https://guppy0130.atlassian.net
+	Email string `mapstructure:"email"` // your email to sign into atlassian
+	Token string `mapstructure:"token"` // a token for basic auth (tested only with cloud)
+	Url   string `mapstructure:"url"`   // root URL; e.g., https://guppy0130.atlassian.net
+	Auth  Auth   `mapstructure:"auth"`  // how to sign in, if not with email and token
+
+	TokenSource  string `mapstructure:"token_source"`  // where the token comes from, if not the config
+	TokenEnv     string `mapstructure:"token_env"`     // the variable it's in, for token_source: env
+	TokenCommand string `mapstructure:"token_command"` // what prints it, for token_source: command
+
 	LogFormat   logger.LoggerFormat `mapstructure:"logformat"`   // json or text
 	AccentColor lipgloss.Color      `mapstructure:"accentcolor"` // accent color
+
+	KeyPreset string              `mapstructure:"key_preset"` // default or vim
+	Keys      map[string][]string `mapstructure:"keys"`       // the keys for actions, by action name, over the preset's
+
+	Queries map[string]string `mapstructure:"queries"` // saved jql, by lowercased name, for the palette
+
+	Profiles       map[string]Profile `mapstructure:"profiles"`        // named jira instances, by lowercased name
+	DefaultProfile string             `mapstructure:"default_profile"` // the one used without --profile
+	Profile        string             `mapstructure:"-"`               // the one in use
+
+	// where settings came from, to say where problems with them are
+	file    string            // the config file read, if there was one
+	lines   map[string]int    // the line each key is on in the file, by its dotted path
+	sources map[string]string // keys set by a flag or environment variable, and which
 }
 
-func LoadViper() Config {
-	var config Config
+// a jira instance and how to sign into it
+type Profile struct {
+	Name  string `mapstructure:"-"`
+	Email string `mapstructure:"email"`
+	Token string `mapstructure:"token"` // an api token for basic auth, or a personal access token for bearer
+	Url   string `mapstructure:"url"`
+	Auth  Auth   `mapstructure:"auth"`
+
+	TokenSource  string `mapstructure:"token_source"`  // one of the TokenSource* constants, config if empty
+	TokenEnv     string `mapstructure:"token_env"`     // for token_source: env
+	TokenCommand string `mapstructure:"token_command"` // for token_source: command
 
-	viper.SetDefault("LogFormat", logger.LoggerFormatJSON)
-	viper.SetDefault("AccentColor", lipgloss.Color("57"))
+	key string // where it's set in the config: profiles.<name>, or nothing for the top level
+}
+
+// ways of signing into jira
+const (
+	AuthBasic   = "basic"   // email and api token; jira cloud
+	AuthBearer  = "bearer"  // a personal access token; jira server and data center
+	AuthOAuth2  = "oauth2"  // an oauth 2.0 (3LO) app; jira cloud
+	AuthSession = "session" // a session cookie; jira server and data center
+)
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
+// how to sign into jira. email and token live on the profile, since basic
+// auth needs nothing else.
+type Auth struct {
+	Type string `mapstructure:"type"` // one of the Auth* constants, basic if empty
+
+	// session auth signs in with a username and password, or takes a cookie
+	// from a browser that already has, e.g. "JSESSIONID=..."
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Cookie   string `mapstructure:"cookie"`
+
+	OAuth2 OAuth2 `mapstructure:"oauth2"`
+}
+
+// an oauth 2.0 (3LO) app made at developer.atlassian.com
+type OAuth2 struct {
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	Scopes       []string `mapstructure:"scopes"`        // offline_access is needed to stay signed in
+	RedirectPort int      `mapstructure:"redirect_port"` // the app's callback url is http://localhost:<port>/callback
+	TokenFile    string   `mapstructure:"token_file"`    // where tokens are kept between runs
+}
+
+// where the oauth app's callback listens unless the config says otherwise
+const DefaultRedirectPort = 8085
+
+// what the email, token and url at the top level of the config are called
+// when they're treated as a profile
+const DefaultProfileName = "default"
+
+// every profile's name, sorted
+func (c Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// the profile in use
+func (c Config) Active() Profile {
+	return c.Profiles[c.Profile]
+}
 
-	if config_dir, err := os.UserConfigDir(); err != nil {
-		viper.AddConfigPath(filepath.Join(config_dir, "go-jira-tui"))
+// the preset's keys, with the keys section over them
+func (c Config) KeyMap() keymap.KeyMap {
+	keys, _ := keymap.Remap(c.KeyPreset, c.Keys)
+	return keys
+}
+
+// there's no config file, and nothing in the environment either, so
+// there's a config to be made
+var ErrNoConfig = errors.New("no config file, and no jira to talk to")
+
+// where the config lives, e.g. ~/.config/go-jira-tui
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
 	}
-	viper.AddConfigPath(".")
+	return filepath.Join(dir, "go-jira-tui"), nil
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("no config file? %w", err))
+// the config file to write: the one --config names, or the usual one
+func FilePath(flags *pflag.FlagSet) (string, error) {
+	if path, _ := flags.GetString("config"); path != "" {
+		return path, nil
 	}
-	err := viper.Unmarshal(&config)
+	dir, err := Dir()
 	if err != nil {
-		panic(fmt.Errorf("bad config file? %w", err))
+		return "", err
+	}
+	return filepath.Join(dir, "config.yml"), nil
+}
+
+// each profile's oauth tokens get a file next to the config
+func defaultTokenFile(profile string) string {
+	dir, err := Dir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "oauth2-"+profile+".json")
+}
+
+// the profile, with what's left out filled in
+func (p Profile) WithDefaults() Profile {
+	if p.Auth.OAuth2.RedirectPort == 0 {
+		p.Auth.OAuth2.RedirectPort = DefaultRedirectPort
+	}
+	if p.TokenEnv == "" {
+		p.TokenEnv = DefaultTokenEnv
+	}
+	// e.g. "$HOME/.jira/oauth2.json"
+	p.Auth.OAuth2.TokenFile = os.ExpandEnv(p.Auth.OAuth2.TokenFile)
+	if p.Auth.OAuth2.TokenFile == "" {
+		p.Auth.OAuth2.TokenFile = defaultTokenFile(p.Name)
 	}
+	return p
+}
 
-	// validate?
-	if config.Email == "" || config.Token == "" || config.Url == "" {
-		panic(fmt.Errorf("part of config is empty: %+v", config))
+// write a config that signs into profile to path, leaving out what's empty.
+// only the user gets to read it, since it has their credentials in it.
+func Write(path string, profile Profile) error {
+	v := viper.New()
+	set := func(key string, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("url", profile.Url)
+	set("email", profile.Email)
+	set("token", profile.Token)
+	if profile.TokenSource != TokenSourceConfig {
+		set("token_source", profile.TokenSource)
+	}
+	if profile.Auth.Type != "" && profile.Auth.Type != AuthBasic {
+		set("auth.type", profile.Auth.Type)
 	}
+	set("auth.username", profile.Auth.Username)
+	set("auth.password", profile.Auth.Password)
+	set("auth.cookie", profile.Auth.Cookie)
+	set("auth.oauth2.client_id", profile.Auth.OAuth2.ClientID)
+	set("auth.oauth2.client_secret", profile.Auth.OAuth2.ClientSecret)
 
-	return config
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("writing the config: %w", err)
+	}
+	v.SetConfigPermissions(0o600)
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("writing the config: %w", err)
+	}
+	return nil
+}
+
+// the flags that override the config and environment, for the app and its
+// subcommands alike
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String("config", "", "config file to read, instead of looking for config.yml")
+	flags.String("profile", "", "which profile in the config to sign into")
+	flags.String("url", "", "jira's root url; e.g., https://guppy0130.atlassian.net")
+	flags.String("log-format", "", "json or text (default json)")
+	flags.String("accent-color", "", "accent color, as an ansi number or hex (default 57)")
+}
+
+// the keys flags set, and which flag sets each
+var flagKeys = map[string]string{
+	"profile":     "profile",
+	"url":         "url",
+	"logformat":   "log-format",
+	"accentcolor": "accent-color",
+}
+
+// the environment variable key is read from
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// every key in t that can be set by itself, e.g. auth.oauth2.client_id.
+// maps like profiles are left to the file, since there's no knowing what's
+// in them.
+func settingKeys(prefix string, t reflect.Type) []string {
+	keys := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		switch {
+		case key == "-" || !field.IsExported() || field.Type.Kind() == reflect.Map:
+			continue
+		case key == "":
+			// like mapstructure does
+			key = strings.ToLower(field.Name)
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, settingKeys(prefix+key+".", field.Type)...)
+			continue
+		}
+		keys = append(keys, prefix+key)
+	}
+	return keys
+}
+
+// the keys a profile has, which the environment and flags set on the
+// profile in use
+var profileKeys = settingKeys("", reflect.TypeFor[Profile]())
+
+// read the config, and check there's nothing wrong with it or the profile
+// in use. problems with other profiles wait until they're switched to.
+func LoadViper(flags *pflag.FlagSet) (Config, error) {
+	config, err := Read(flags)
+	if err != nil {
+		return config, err
+	}
+	if problems := config.ValidateInUse(); len(problems) > 0 {
+		return config, problems
+	}
+	return config, nil
+}
+
+// read the config, with the environment over it and flags over both, without
+// checking it. the environment and flags set the profile in use rather
+// than the top level. there needn't be a file if the environment says
+// enough; if it doesn't, that's ErrNoConfig. each read starts from scratch,
+// so reading again when the file's saved sees only what's there now.
+func Read(flags *pflag.FlagSet) (Config, error) {
+	var config Config
+	v := viper.New()
+
+	v.SetDefault("LogFormat", logger.LoggerFormatJSON)
+	v.SetDefault("AccentColor", lipgloss.Color("57"))
+
+	if path, _ := flags.GetString("config"); path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+
+		if config_dir, err := Dir(); err == nil {
+			v.AddConfigPath(config_dir)
+		}
+		v.AddConfigPath(".")
+	}
+
+	err := v.ReadInConfig()
+	// --config naming a file that isn't there yet is as good as not finding one
+	found := !errors.As(err, &viper.ConfigFileNotFoundError{}) && !errors.Is(err, fs.ErrNotExist)
+	if err != nil && found {
+		return config, fmt.Errorf("bad config file? %w", err)
+	}
+	// what's at the top level of the file alone, before the environment and
+	// flags have their say
+	var top Profile
+	if err := v.Unmarshal(&top); err != nil {
+		return config, fmt.Errorf("bad config file? %w", err)
+	}
+
+	// viper only looks in the environment for keys it already knows, so
+	// tell it about every one
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	envKeys := append(settingKeys("", reflect.TypeFor[Config]()), "profile")
+	config.sources = make(map[string]string)
+	for _, key := range envKeys {
+		v.BindEnv(key)
+		if _, ok := os.LookupEnv(envName(key)); ok {
+			config.sources[key] = "$" + envName(key)
+		}
+	}
+	for key, name := range flagKeys {
+		flag := flags.Lookup(name)
+		v.BindPFlag(key, flag)
+		if flag.Changed {
+			config.sources[key] = "--" + name
+		}
+	}
+
+	if err := v.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("bad config file? %w", err)
+	}
+	if found {
+		config.file = v.ConfigFileUsed()
+		config.lines = keyLines(config.file)
+	}
+
+	// email, token and url at the top level of the file make a profile of
+	// their own, so configs from before profiles keep working
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]Profile)
+	}
+	if _, ok := config.Profiles[DefaultProfileName]; top.Url != "" && !ok {
+		config.Profiles[DefaultProfileName] = top
+	}
+
+	// viper lowercases keys, so profile names are too
+	config.Profile = strings.ToLower(cmp.Or(v.GetString("profile"), config.DefaultProfile))
+	if config.Profile == "" {
+		config.Profile = DefaultProfileName
+		if _, ok := config.Profiles[DefaultProfileName]; !ok && len(config.Profiles) > 0 {
+			config.Profile = config.ProfileNames()[0]
+		}
+	}
+
+	// the environment and flags go over the profile in use. without any
+	// profiles they're one of their own, e.g. with no file at all.
+	overrides := viper.New()
+	for _, key := range profileKeys {
+		if _, ok := config.sources[key]; ok {
+			overrides.Set(key, v.Get(key))
+		}
+	}
+	profile, ok := config.Profiles[config.Profile]
+	if len(overrides.AllKeys()) > 0 && (ok || (len(config.Profiles) == 0 && overrides.IsSet("url"))) {
+		if err := overrides.Unmarshal(&profile); err != nil {
+			return config, fmt.Errorf("bad environment or flags? %w", err)
+		}
+		config.Profiles[config.Profile] = profile
+	}
+
+	switch {
+	case len(config.Profiles) == 0 && !found:
+		return config, ErrNoConfig
+	case len(config.Profiles) == 0:
+		return config, fmt.Errorf("no jira to talk to: set a url (or $%s_URL), or add some profiles", EnvPrefix)
+	}
+	for name, profile := range config.Profiles {
+		profile.Name = name
+		if name != DefaultProfileName || v.IsSet("profiles."+name) {
+			profile.key = "profiles." + name
+		}
+		config.Profiles[name] = profile.WithDefaults()
+	}
+	// problems with what was overridden are put down to the override
+	if profile, ok := config.Profiles[config.Profile]; ok && profile.key != "" {
+		for _, key := range profileKeys {
+			if source, ok := config.sources[key]; ok {
+				config.sources[profile.key+"."+key] = source
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// read the config again whenever the file c was read from is saved, flags
+// and environment and all, and hand it to changed. there's nothing to watch
+// without a file.
+func (c Config) Watch(flags *pflag.FlagSet, changed func(Config, error)) {
+	if c.file == "" {
+		return
+	}
+	watcher := viper.New()
+	watcher.SetConfigFile(c.file)
+	watcher.OnConfigChange(func(fsnotify.Event) {
+		changed(Read(flags))
+	})
+	watcher.WatchConfig()
 }
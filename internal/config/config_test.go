@@ -0,0 +1,135 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// reloading the config reads it the same way as the first time, and leaves
+// viper's own instance alone
+func TestReadAgain(t *testing.T) {
+	t.Setenv(EnvPrefix+"_ACCENTCOLOR", "99")
+	flags := configFlags(t, `---
+url: https://example.atlassian.net
+email: me@example.com
+token: secret
+profiles:
+  work:
+    url: fake://
+`, "--profile", "work")
+	first, err := Read(flags)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	for range 3 {
+		again, err := Read(flags)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if !reflect.DeepEqual(again, first) {
+			t.Fatalf("read again as %v, want %v", again, first)
+		}
+	}
+	if first.AccentColor != "99" || first.Profile != "work" {
+		t.Errorf("AccentColor, Profile = %q, %q, want the environment's 99 and the flag's work", first.AccentColor, first.Profile)
+	}
+	if used := viper.ConfigFileUsed(); used != "" {
+		t.Errorf("viper.ConfigFileUsed() = %q, want it untouched", used)
+	}
+}
+
+// the environment and flags go over the profile in use, wherever it is
+func TestReadOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		yml      string
+		env      map[string]string
+		args     []string
+		profiles map[string]Profile // just the url, email and token of each
+		profile  string
+	}{
+		{
+			name: "default_profile",
+			yml:  "default_profile: work\nprofiles:\n  work:\n    url: https://work.example.com\n    email: me@work.example.com\n    token: secret\n",
+			env:  map[string]string{"EMAIL": "other@work.example.com"},
+			args: []string{"--url", "https://override.example.com"},
+			profiles: map[string]Profile{
+				"work": {Url: "https://override.example.com", Email: "other@work.example.com", Token: "secret"},
+			},
+			profile: "work",
+		},
+		{
+			name: "the only profile",
+			yml:  "profiles:\n  work:\n    url: https://work.example.com\n    email: me@work.example.com\n",
+			env:  map[string]string{"URL": "https://override.example.com", "TOKEN": "secret"},
+			profiles: map[string]Profile{
+				"work": {Url: "https://override.example.com", Email: "me@work.example.com", Token: "secret"},
+			},
+			profile: "work",
+		},
+		{
+			name: "the top level, when another's in use",
+			yml:  "url: https://top.example.com\nemail: me@example.com\ntoken: secret\nprofiles:\n  work:\n    url: https://work.example.com\n",
+			env:  map[string]string{"URL": "https://override.example.com"},
+			args: []string{"--profile", "work"},
+			profiles: map[string]Profile{
+				"default": {Url: "https://top.example.com", Email: "me@example.com", Token: "secret"},
+				"work":    {Url: "https://override.example.com"},
+			},
+			profile: "work",
+		},
+		{
+			name: "the top level, in use",
+			yml:  "url: https://top.example.com\nemail: me@example.com\n",
+			env:  map[string]string{"TOKEN": "secret"},
+			profiles: map[string]Profile{
+				"default": {Url: "https://top.example.com", Email: "me@example.com", Token: "secret"},
+			},
+			profile: "default",
+		},
+		{
+			name: "nothing in the file",
+			yml:  "logformat: text\n",
+			env:  map[string]string{"URL": "https://override.example.com", "EMAIL": "me@example.com", "TOKEN": "secret"},
+			profiles: map[string]Profile{
+				"default": {Url: "https://override.example.com", Email: "me@example.com", Token: "secret"},
+			},
+			profile: "default",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(EnvPrefix+"_"+name, value)
+			}
+			conf := readConfig(t, tt.yml, tt.args...)
+			got := make(map[string]Profile)
+			for name, profile := range conf.Profiles {
+				got[name] = Profile{Url: profile.Url, Email: profile.Email, Token: profile.Token}
+			}
+			if !reflect.DeepEqual(got, tt.profiles) || conf.Profile != tt.profile {
+				t.Errorf("Read() = %v using %s, want %v using %s", got, conf.Profile, tt.profiles, tt.profile)
+			}
+		})
+	}
+}
+
+// a lone override isn't a profile, and what's wrong with it is put down to
+// where it came from
+func TestReadOverrideProblems(t *testing.T) {
+	conf := readConfig(t, "profiles:\n  work:\n    url: https://work.example.com\n    email: me@work.example.com\n    token: secret\n", "--url", "not a url")
+	if names := conf.ProfileNames(); !reflect.DeepEqual(names, []string{"work"}) {
+		t.Errorf("ProfileNames() = %v, want only work", names)
+	}
+	problems := conf.Validate()
+	if len(problems) != 1 || problems[0].Key != "profiles.work.url" || problems[0].Where != "--url" {
+		t.Errorf("Validate() = %v, want work's url, from --url", problems)
+	}
+
+	t.Setenv(EnvPrefix+"_TOKEN", "secret")
+	if _, err := Read(configFlags(t, "logformat: text\n")); err == nil {
+		t.Errorf("Read() with only a token = nil error, want one asking for a url")
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// where a profile's token comes from
+const (
+	TokenSourceConfig  = "config"  // the token key, in plaintext
+	TokenSourceKeyring = "keyring" // the os keyring, put there by `go-jira-tui login`
+	TokenSourceEnv     = "env"     // an environment variable, named by token_env
+	TokenSourceCommand = "command" // whatever token_command prints, e.g. `pass show jira`
+)
+
+// the keyring service tokens are kept under, with the profile's name as
+// the user
+const KeyringService = "go-jira-tui"
+
+// what token_env is unless the config says otherwise
+const DefaultTokenEnv = "JIRA_API_TOKEN"
+
+// whether the token's written into the config, rather than kept somewhere
+// else
+func (p Profile) tokenInConfig() bool {
+	return p.TokenSource == "" || p.TokenSource == TokenSourceConfig
+}
+
+func (p Profile) tokenSourceProblems() []Problem {
+	switch p.TokenSource {
+	case "", TokenSourceConfig, TokenSourceKeyring, TokenSourceEnv:
+	case TokenSourceCommand:
+		if p.TokenCommand == "" {
+			return []Problem{{Key: "token_command", Message: "needs a command to get the token from"}}
+		}
+	default:
+		return []Problem{{Key: "token_source", Message: fmt.Sprintf("%q isn't %s, %s, %s or %s", p.TokenSource, TokenSourceConfig, TokenSourceKeyring, TokenSourceEnv, TokenSourceCommand)}}
+	}
+	return nil
+}
+
+// the profile with its token filled in from wherever token_source says.
+// keyrings and commands can be slow, so this waits until the profile's
+// actually signed into.
+func (p Profile) LoadToken() (Profile, error) {
+	switch p.TokenSource {
+	case TokenSourceKeyring:
+		token, err := keyring.Get(KeyringService, p.Name)
+		if errors.Is(err, keyring.ErrNotFound) {
+			return p, fmt.Errorf("no token in the keyring for profile %q; run go-jira-tui login --profile %s", p.Name, p.Name)
+		}
+		if err != nil {
+			return p, fmt.Errorf("reading the token from the keyring: %w", err)
+		}
+		p.Token = token
+	case TokenSourceEnv:
+		p.Token = os.Getenv(p.TokenEnv)
+		if p.Token == "" {
+			return p, fmt.Errorf("$%s is empty, and profile %q gets its token from it", p.TokenEnv, p.Name)
+		}
+	case TokenSourceCommand:
+		token, err := runTokenCommand(p.TokenCommand)
+		if err != nil {
+			return p, err
+		}
+		p.Token = token
+	}
+	return p, nil
+}
+
+// run command in a shell and take what it prints, like git's credential
+// helpers. pass and friends print the secret on the first line.
+func runTokenCommand(command string) (string, error) {
+	shell := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		shell = exec.Command("cmd", "/C", command)
+	}
+	out, err := shell.Output()
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("getting the token from %q: %w", command, err)
+	}
+	token, _, _ := strings.Cut(string(out), "\n")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("getting the token from %q: it didn't print anything", command)
+	}
+	return token, nil
+}
+
+// keep token where token_source says, for `go-jira-tui login`
+func (p Profile) SaveToken(token string) error {
+	if err := p.TokenSaveable(); err != nil {
+		return err
+	}
+	if err := keyring.Set(KeyringService, p.Name, token); err != nil {
+		return fmt.Errorf("saving the token to the keyring: %w", err)
+	}
+	return nil
+}
+
+// only the keyring can be written to; the rest are for the user to fill in
+func (p Profile) TokenSaveable() error {
+	switch p.TokenSource {
+	case TokenSourceKeyring:
+		return nil
+	case TokenSourceEnv:
+		return fmt.Errorf("profile %q reads its token from $%s, so set it there", p.Name, p.TokenEnv)
+	case TokenSourceCommand:
+		return fmt.Errorf("profile %q gets its token from %q, so save it wherever that reads from", p.Name, p.TokenCommand)
+	}
+	return fmt.Errorf("profile %q keeps its token in the config; set token_source: %s to save it in the keyring instead", p.Name, TokenSourceKeyring)
+}
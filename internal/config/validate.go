@@ -0,0 +1,347 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+	"github.com/guppy0130/go-jira-tui/pkg/cmd/logger"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// something wrong with the config, and where it is
+type Problem struct {
+	Profile string // the profile it's in, or nothing for the rest of the config
+	Key     string // e.g. profiles.work.url
+	Message string
+	Where   string // config.yml:12, $JIRA_TUI_URL or --url, if it's known
+}
+
+func (p Problem) Error() string {
+	message := p.Message
+	if p.Key != "" {
+		message = p.Key + ": " + message
+	}
+	if p.Where != "" {
+		message = p.Where + ": " + message
+	}
+	return message
+}
+
+// everything wrong with the config
+type Problems []Problem
+
+func (p Problems) Error() string {
+	if len(p) == 1 {
+		return p[0].Error()
+	}
+	lines := []string{fmt.Sprintf("%d problems:", len(p))}
+	for _, problem := range p {
+		lines = append(lines, "  "+problem.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var logFormats = []logger.LoggerFormat{logger.LoggerFormatJSON, logger.LoggerFormatText}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// an ansi color from 0 to 255, or a hex one
+func validColor(color lipgloss.Color) bool {
+	if n, err := strconv.Atoi(string(color)); err == nil {
+		return n >= 0 && n <= 255
+	}
+	return hexColor.MatchString(string(color))
+}
+
+// everything wrong with the config, in every profile
+func (c Config) Validate() Problems {
+	problems := Problems{}
+	if !slices.Contains(logFormats, c.LogFormat) {
+		problems = append(problems, Problem{Key: "logformat", Message: fmt.Sprintf("%q isn't %s or %s", c.LogFormat, logger.LoggerFormatJSON, logger.LoggerFormatText)})
+	}
+	if !validColor(c.AccentColor) {
+		problems = append(problems, Problem{Key: "accentcolor", Message: fmt.Sprintf("%q isn't a color like 57 or #7d56f4", c.AccentColor)})
+	}
+	if _, ok := keymap.Presets[c.KeyPreset]; c.KeyPreset != "" && !ok {
+		problems = append(problems, Problem{Key: "key_preset", Message: fmt.Sprintf("%q isn't %s", c.KeyPreset, strings.Join(keymap.PresetNames(), " or "))})
+	}
+	_, keyProblems := keymap.Remap(c.KeyPreset, c.Keys)
+	for _, problem := range keyProblems {
+		problems = append(problems, Problem{Key: "keys." + problem.Action, Message: problem.Message})
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Queries)) {
+		if strings.TrimSpace(c.Queries[name]) == "" {
+			problems = append(problems, Problem{Key: "queries." + name, Message: "needs some jql to run"})
+		}
+	}
+	others := "only " + strings.Join(c.ProfileNames(), ", ")
+	if _, ok := c.Profiles[strings.ToLower(c.DefaultProfile)]; c.DefaultProfile != "" && !ok {
+		problems = append(problems, Problem{Key: "default_profile", Message: fmt.Sprintf("there's no profile named %q, %s", c.DefaultProfile, others)})
+	}
+	// one that default_profile picked has been dealt with
+	if _, ok := c.Profiles[c.Profile]; !ok && c.sources["profile"] != "" {
+		problems = append(problems, Problem{Key: "profile", Message: fmt.Sprintf("there's no profile named %q, %s", c.Profile, others)})
+	}
+	for _, name := range c.ProfileNames() {
+		for _, problem := range c.Profiles[name].problems() {
+			problem.Profile = name
+			problems = append(problems, problem)
+		}
+	}
+	for i := range problems {
+		problems[i].Where = c.where(problems[i].Key)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// what's wrong with the config, leaving out other profiles than the one in
+// use; problems with those wait until they're switched to
+func (c Config) ValidateInUse() Problems {
+	problems := slices.DeleteFunc(c.Validate(), func(problem Problem) bool {
+		return problem.Profile != "" && problem.Profile != c.Profile
+	})
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// whether there's enough to sign in with. the fake backend (fake:// urls)
+// doesn't need credentials.
+func (p Profile) Validate() error {
+	if problems := p.problems(); len(problems) > 0 {
+		return Problems(problems)
+	}
+	return nil
+}
+
+func (p Profile) problems() []Problem {
+	problems := make([]Problem, 0)
+	add := func(key string, message string) {
+		problems = append(problems, Problem{Key: key, Message: message})
+	}
+	u, err := url.Parse(p.Url)
+	switch {
+	case p.Url == "":
+		add("url", "needs a url")
+	case strings.HasPrefix(p.Url, "fake://"):
+		return nil
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		add("url", fmt.Sprintf("%q isn't a url like https://your-site.atlassian.net", p.Url))
+	}
+
+	problems = append(problems, p.tokenSourceProblems()...)
+	// tokens kept elsewhere are only looked up when they're needed
+	missingToken := p.tokenInConfig() && p.Token == ""
+	switch p.Auth.Type {
+	case "", AuthBasic:
+		if p.Email == "" {
+			add("email", "needs an email for basic auth")
+		}
+		if missingToken {
+			add("token", "needs an api token for basic auth")
+		}
+	case AuthBearer:
+		if missingToken {
+			add("token", "needs a personal access token for bearer auth")
+		}
+	case AuthSession:
+		if p.Auth.Cookie == "" && (p.Auth.Username == "" || p.Auth.Password == "") {
+			add("auth", "needs a cookie, or a username and password, for session auth")
+		}
+	case AuthOAuth2:
+		if p.Auth.OAuth2.ClientID == "" {
+			add("auth.oauth2.client_id", "needs the oauth app's client id")
+		}
+		if p.Auth.OAuth2.ClientSecret == "" {
+			add("auth.oauth2.client_secret", "needs the oauth app's client secret")
+		}
+	default:
+		add("auth.type", fmt.Sprintf("%q isn't %s, %s, %s or %s", p.Auth.Type, AuthBasic, AuthBearer, AuthOAuth2, AuthSession))
+	}
+	// easily pasted in, and makes for a confusing 401
+	if p.tokenInConfig() && strings.TrimSpace(p.Token) != p.Token {
+		add("token", "has spaces or newlines around it")
+	}
+
+	if p.key != "" {
+		for i := range problems {
+			problems[i].Key = p.key + "." + problems[i].Key
+		}
+	}
+	return problems
+}
+
+// where key was set: the flag or environment variable, or the line it (or
+// what it's in) is on in the config file. nothing if it isn't set anywhere,
+// e.g. a key that's missing.
+func (c Config) where(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	for {
+		if line, ok := c.lines[key]; ok {
+			if line == 0 {
+				return c.file
+			}
+			return fmt.Sprintf("%s:%d", c.file, line)
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return ""
+		}
+		key = key[:i]
+	}
+}
+
+// the line each key is on in a yaml config, by its lowercased dotted path,
+// the way viper has them. other formats only say which keys they have, on
+// line 0.
+func keyLines(path string) map[string]int {
+	if ext := filepath.Ext(path); ext != ".yml" && ext != ".yaml" {
+		return fileKeys(path)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+	lines := make(map[string]int)
+	var walk func(prefix string, node *yaml.Node)
+	walk = func(prefix string, node *yaml.Node) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(prefix, child)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := prefix + strings.ToLower(node.Content[i].Value)
+				lines[key] = node.Content[i].Line
+				walk(key+".", node.Content[i+1])
+			}
+		}
+	}
+	walk("", &doc)
+	return lines
+}
+
+// stands in for secrets wherever the config's printed
+const redacted = "[redacted]"
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// the config with its secrets redacted, and its profiles'
+func (c Config) redacted() Config {
+	c.Token, c.Auth = redact(c.Token), c.Auth.redacted()
+	if c.Profiles != nil {
+		profiles := make(map[string]Profile, len(c.Profiles))
+		for name, profile := range c.Profiles {
+			profiles[name] = profile.redacted()
+		}
+		c.Profiles = profiles
+	}
+	return c
+}
+
+func (p Profile) redacted() Profile {
+	p.Token, p.Auth = redact(p.Token), p.Auth.redacted()
+	return p
+}
+
+func (a Auth) redacted() Auth {
+	a.Password, a.Cookie = redact(a.Password), redact(a.Cookie)
+	a.OAuth2.ClientSecret = redact(a.OAuth2.ClientSecret)
+	return a
+}
+
+// configs print, log and marshal with their secrets redacted, so they're
+// safe to log however the logger goes about it
+func (c Config) String() string {
+	type plain Config
+	return fmt.Sprintf("%+v", plain(c.redacted()))
+}
+
+func (c Config) LogValue() slog.Value {
+	type plain Config
+	return slog.AnyValue(plain(c.redacted()))
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	return json.Marshal(plain(c.redacted()))
+}
+
+func (p Profile) String() string {
+	type plain Profile
+	return fmt.Sprintf("%+v", plain(p.redacted()))
+}
+
+func (p Profile) LogValue() slog.Value {
+	type plain Profile
+	return slog.AnyValue(plain(p.redacted()))
+}
+
+func (p Profile) MarshalJSON() ([]byte, error) {
+	type plain Profile
+	return json.Marshal(plain(p.redacted()))
+}
+
+func (a Auth) String() string {
+	type plain Auth
+	return fmt.Sprintf("%+v", plain(a.redacted()))
+}
+
+func (a Auth) LogValue() slog.Value {
+	type plain Auth
+	return slog.AnyValue(plain(a.redacted()))
+}
+
+func (a Auth) MarshalJSON() ([]byte, error) {
+	type plain Auth
+	return json.Marshal(plain(a.redacted()))
+}
+
+// every key in a config that isn't yaml, and what they're in
+func fileKeys(path string) map[string]int {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if v.ReadInConfig() != nil {
+		return nil
+	}
+	keys := make(map[string]int)
+	for _, key := range v.AllKeys() {
+		for {
+			keys[key] = 0
+			i := strings.LastIndex(key, ".")
+			if i < 0 {
+				break
+			}
+			key = key[:i]
+		}
+	}
+	return keys
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// a command line of args, reading yml as the config file
+func configFlags(t *testing.T, yml string, args ...string) *pflag.FlagSet {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(flags)
+	if err := flags.Parse(append([]string{"--config", path}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+// read yml as the config file, with args as the command line
+func readConfig(t *testing.T, yml string, args ...string) Config {
+	t.Helper()
+	conf, err := Read(configFlags(t, yml, args...))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return conf
+}
+
+func TestValidateFile(t *testing.T) {
+	conf := readConfig(t, `---
+url: https://example.atlassian.net
+email: me@example.com
+token: secret
+logformat: xml
+profiles:
+  Work:
+    url: not a url
+    auth:
+      type: bearer
+  cloud:
+    url: https://cloud.atlassian.net
+    auth:
+      type: oauth2
+      oauth2:
+        client_id: abc
+`)
+	var got []string
+	for _, problem := range conf.Validate() {
+		got = append(got, strings.TrimPrefix(problem.Error(), conf.file))
+	}
+	want := []string{
+		`:5: logformat: "xml" isn't json or text`,
+		// client_secret isn't in the file, so it's wherever oauth2 is
+		`:15: profiles.cloud.auth.oauth2.client_secret: needs the oauth app's client secret`,
+		`:8: profiles.work.url: "not a url" isn't a url like https://your-site.atlassian.net`,
+		`:7: profiles.work.token: needs a personal access token for bearer auth`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateSources(t *testing.T) {
+	t.Setenv(EnvPrefix+"_ACCENTCOLOR", "purple")
+	t.Setenv(EnvPrefix+"_LOGFORMAT", "xml")
+	conf := readConfig(t, `---
+url: https://example.atlassian.net
+email: me@example.com
+token: secret
+logformat: text
+`, "--log-format", "yaml", "--profile", "nope")
+	got := make(map[string]string)
+	for _, problem := range conf.Validate() {
+		got[problem.Key] = problem.Where
+	}
+	want := map[string]string{
+		// the flag's over the environment, which is over the file
+		"logformat":   "--log-format",
+		"accentcolor": "$" + EnvPrefix + "_ACCENTCOLOR",
+		"profile":     "--profile",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Validate() found problems at %v, want %v", got, want)
+	}
+	if conf.LogFormat != "yaml" {
+		t.Errorf("LogFormat = %q, want the flag's yaml", conf.LogFormat)
+	}
+}
+
+func TestKeyLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yml := "url: x\nprofiles:\n  Work:\n    auth:\n      type: bearer\n"
+	if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"url": 1, "profiles": 2, "profiles.work": 3, "profiles.work.auth": 4, "profiles.work.auth.type": 5}
+	if got := keyLines(path); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("keyLines() = %v, want %v", got, want)
+	}
+	// only yaml has lines worth pointing at, but json still has keys
+	path = filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"url": "x", "profiles": {"Work": {"auth": {"type": "bearer"}}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]int{"url": 0, "profiles": 0, "profiles.work": 0, "profiles.work.auth": 0, "profiles.work.auth.type": 0}
+	if got := keyLines(path); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("keyLines() of json = %v, want %v", got, want)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	conf := Config{
+		file:    "config.yml",
+		lines:   map[string]int{"profiles": 3, "profiles.work": 4, "profiles.work.auth": 6, "queries": 0},
+		sources: map[string]string{"url": "$JIRA_TUI_URL"},
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"url", "$JIRA_TUI_URL"},
+		{"profiles.work.auth", "config.yml:6"},
+		{"profiles.work.auth.oauth2.client_id", "config.yml:6"},
+		{"profiles.work.token", "config.yml:4"},
+		// somewhere in the file, without a line to point at
+		{"queries.mine", "config.yml"},
+		// not in the file, so it isn't put down to it
+		{"logformat", ""},
+		{"email", ""},
+	}
+	for _, tt := range tests {
+		if got := conf.where(tt.key); got != tt.want {
+			t.Errorf("where(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	secrets := []string{"top-token", "top-password", "top-cookie", "top-client-secret", "work-token", "work-password", "work-cookie", "work-client-secret"}
+	auth := func(owner string) Auth {
+		return Auth{Type: AuthSession, Username: owner + "-user", Password: owner + "-password", Cookie: owner + "-cookie", OAuth2: OAuth2{ClientID: owner + "-client", ClientSecret: owner + "-client-secret"}}
+	}
+	work := Profile{Name: "work", Email: "work@example.com", Token: "work-token", Url: "https://work.example.com", Auth: auth("work")}
+	conf := Config{Email: "top@example.com", Token: "top-token", Auth: auth("top"), Profiles: map[string]Profile{"work": work}}
+
+	logged := func(handler func(io.Writer) slog.Handler) func(any) string {
+		return func(value any) string {
+			var b strings.Builder
+			slog.New(handler(&b)).Debug("update", "msg", value)
+			return b.String()
+		}
+	}
+	printers := map[string]func(any) string{
+		"%v":  func(value any) string { return fmt.Sprintf("%v", value) },
+		"%+v": func(value any) string { return fmt.Sprintf("%+v", value) },
+		"%s":  func(value any) string { return fmt.Sprintf("%s", value) },
+		"the json handler": logged(func(w io.Writer) slog.Handler {
+			return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+		}),
+		"the text handler": logged(func(w io.Writer) slog.Handler {
+			return slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+		}),
+		// e.g. a msg carrying a new config, which the json handler marshals
+		"json inside something else": func(value any) string {
+			printed, err := json.Marshal(struct{ Value any }{value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(printed)
+		},
+	}
+	for how, print := range printers {
+		for name, value := range map[string]any{"config": conf, "profile": work, "auth": work.Auth} {
+			printed := print(value)
+			for _, secret := range secrets {
+				if strings.Contains(printed, secret) {
+					t.Errorf("%s printed with %s shows %q: %s", name, how, secret, printed)
+				}
+			}
+			if !strings.Contains(printed, redacted) {
+				t.Errorf("%s printed with %s has nothing redacted: %s", name, how, printed)
+			}
+		}
+	}
+	// what's not secret is still there to debug with
+	printed := fmt.Sprint(conf)
+	for _, shown := range []string{"top@example.com", "top-user", "top-client", "work@example.com", "https://work.example.com"} {
+		if !strings.Contains(printed, shown) {
+			t.Errorf("config printed without %q: %s", shown, printed)
+		}
+	}
+	// nothing's made up for secrets that aren't set
+	if printed := fmt.Sprint(Auth{Type: AuthBasic}); strings.Contains(printed, redacted) {
+		t.Errorf("an empty auth printed as %s", printed)
+	}
+}
@@ -0,0 +1,49 @@
+package jira
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+)
+
+// sends the user off to approve an oauth login, e.g. by opening a browser
+// at authURL. nil means there's nobody around to approve one, so profiles
+// that need approving fail instead.
+type Approver func(authURL string)
+
+// an http client that signs requests the way the profile's auth says to,
+// and the url requests go to. that's the profile's url, except for oauth,
+// which goes through atlassian's api gateway.
+func authClient(profile config.Profile, approve Approver) (*http.Client, string, error) {
+	auth := profile.Auth
+	switch auth.Type {
+	case "", config.AuthBasic:
+		transport := jira.BasicAuthTransport{Username: profile.Email, Password: profile.Token}
+		return transport.Client(), profile.Url, nil
+
+	case config.AuthBearer:
+		transport := jira.PATAuthTransport{Token: profile.Token}
+		return transport.Client(), profile.Url, nil
+
+	// a cookie from the browser is used as is; otherwise jira hands one
+	// over for the username and password
+	case config.AuthSession:
+		transport := jira.CookieAuthTransport{
+			Username: auth.Username,
+			Password: auth.Password,
+			AuthURL:  strings.TrimSuffix(profile.Url, "/") + "/rest/auth/1/session",
+		}
+		if auth.Cookie != "" {
+			header := http.Header{"Cookie": {auth.Cookie}}
+			transport.SessionObject = (&http.Request{Header: header}).Cookies()
+		}
+		return transport.Client(), profile.Url, nil
+
+	case config.AuthOAuth2:
+		return oauthClient(profile, approve)
+	}
+	return nil, "", fmt.Errorf("auth type %q isn't one we know", auth.Type)
+}
@@ -0,0 +1,188 @@
+package jira
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+)
+
+// everything the views need from a jira instance. JiraData talks to a real
+// server, FakeBackend serves fixtures so the app can run offline.
+type Backend interface {
+	// the user we're signed in as
+	Self() *jira.User
+	// host we're talking to, for the status bar
+	Host() string
+
+	// a page of all the boards
+	GetBoards(startAt int) (*jira.BoardsList, error)
+	// a page of the sprints that belong to a board
+	GetSprintsForBoard(boardID int, startAt int) (*jira.SprintsList, error)
+	// a page of the issues on a board, as the board's filter has them
+	GetIssuesForBoard(board jira.Board, startAt int) (*IssuesPage, error)
+	// a page of the issues in a board's backlog, i.e. not in any active or
+	// closed sprint
+	GetBacklogForBoard(board jira.Board, startAt int) (*IssuesPage, error)
+	// a board's columns and which statuses land in each
+	GetBoardConfiguration(boardID int) (*jira.BoardConfiguration, error)
+	// a page of the issues in a sprint
+	GetIssuesForSprint(sprint jira.Sprint, startAt int) (*IssuesPage, error)
+	// a page of the issues matching some JQL
+	SearchIssues(jql string, startAt int) (*IssuesPage, error)
+	// a single issue, with all its fields and their names
+	GetIssue(issueID string) (*jira.Issue, error)
+
+	// the transitions an issue can take from where it is, with the fields
+	// each one asks for
+	GetTransitions(issueID string) ([]Transition, error)
+	// move an issue along a transition. fields maps each field the
+	// transition asks for to the id of one of its allowed values.
+	DoTransition(issueID string, transitionID string, fields map[string]string) error
+
+	// the projects we can make issues in. their issue types, and the fields
+	// each one asks for, are fetched as they're picked.
+	GetCreateMeta() ([]CreateMetaProject, error)
+	// the issue types that can be made in a project, without their fields
+	GetCreateIssueTypes(projectKey string) ([]CreateMetaIssueType, error)
+	// the fields an issue type asks for in a project, keyed by id
+	GetCreateFields(projectKey string, issueTypeID string) (map[string]FieldMeta, error)
+	// make an issue. fields are keyed by id, with values shaped the way
+	// jira takes them, e.g. {"priority": {"id": "3"}}.
+	CreateIssue(fields map[string]any) (*jira.Issue, error)
+	// the fields of an issue that can be changed, and what to
+	GetEditMeta(issueID string) (map[string]FieldMeta, error)
+	// change some of an issue's fields. values are shaped like CreateIssue's,
+	// and nil clears a field.
+	UpdateIssue(issueID string, fields map[string]any) error
+	// people whose name or email matches query, for picking someone for a
+	// user field. an assignee is picked from who scope can be assigned to.
+	FindUsers(query string, scope UserScope) ([]jira.User, error)
+	// labels already in use that start with prefix
+	SuggestLabels(prefix string) ([]string, error)
+
+	// comments, with bodies in jira wiki markup
+	AddComment(issueID string, body string) (*jira.Comment, error)
+	UpdateComment(issueID string, commentID string, body string) (*jira.Comment, error)
+	DeleteComment(issueID string, commentID string) error
+
+	// time logged against an issue, oldest first
+	GetWorklogs(issueID string) ([]jira.WorklogRecord, error)
+	// log time against an issue. estimate says what happens to the
+	// remaining estimate.
+	AddWorklog(issueID string, worklog jira.WorklogRecord, estimate RemainingEstimate) error
+}
+
+// how many results to ask for at once; jira caps most endpoints at 50
+const pageSize = 50
+
+// one page of issues. the search endpoints don't return isLast like the
+// agile ones do, so it's worked out from Total.
+type IssuesPage struct {
+	Issues  []jira.Issue
+	StartAt int
+	Total   int
+}
+
+func (p IssuesPage) IsLast() bool {
+	return len(p.Issues) == 0 || p.StartAt+len(p.Issues) >= p.Total
+}
+
+// go-jira's Transition leaves out what values a field allows, which is what
+// we need to ask for e.g. a resolution
+type Transition struct {
+	ID     string               `json:"id"`
+	Name   string               `json:"name"`
+	To     jira.Status          `json:"to"`
+	Fields map[string]FieldMeta `json:"fields"`
+}
+
+// what jira says about a field on a screen: transitions, createmeta and
+// editmeta all describe their fields this way
+type FieldMeta struct {
+	Required      bool           `json:"required"`
+	Name          string         `json:"name"`
+	Schema        FieldSchema    `json:"schema"`
+	AllowedValues []AllowedValue `json:"allowedValues"`
+}
+
+// what kind of value a field holds, e.g. {"type": "array", "items":
+// "component", "system": "components"}
+type FieldSchema struct {
+	Type   string `json:"type"`
+	Items  string `json:"items"`  // what arrays hold
+	System string `json:"system"` // set for jira's own fields
+	Custom string `json:"custom"` // the plugin type, for custom fields
+}
+
+type AllowedValue struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Value string `json:"value"` // custom field options have a value instead of a name
+}
+
+// who a user search is narrowed to: people who can be assigned IssueKey,
+// or issues in Project while one's being made. neither is anyone at all.
+type UserScope struct {
+	IssueKey string
+	Project  string
+}
+
+// go-jira's createmeta leaves the fields untyped. IssueTypes, and each
+// one's Fields, are nil until they're fetched.
+type CreateMetaProject struct {
+	ID         string                `json:"id"`
+	Key        string                `json:"key"`
+	Name       string                `json:"name"`
+	IssueTypes []CreateMetaIssueType `json:"issuetypes"`
+}
+
+type CreateMetaIssueType struct {
+	ID      string               `json:"id"`
+	Name    string               `json:"name"`
+	Subtask bool                 `json:"subtask"`
+	Fields  map[string]FieldMeta `json:"fields"`
+}
+
+// what logging work does to an issue's remaining estimate, as jira's
+// adjustEstimate and newEstimate parameters have it
+type RemainingEstimate struct {
+	Adjust string `url:"adjustEstimate"`        // one of the estimate* constants
+	New    string `url:"newEstimate,omitempty"` // what it's set to with estimateNew, e.g. "2d"
+}
+
+const (
+	estimateAuto  = "auto"  // take the time logged off it
+	estimateLeave = "leave" // don't touch it
+	estimateNew   = "new"   // set it to something else
+)
+
+// urls with this scheme get the fake backend instead of a real client
+const FakeScheme = "fake"
+
+// pick a backend based on the profile's url: fake:// serves the bundled
+// fixtures, everything else is a real jira server, signed into however the
+// profile says. fake://?fail=429 makes the first request fail, which is
+// handy for poking at error handling; pagesize and latency (e.g.
+// fake://?pagesize=2&latency=300ms) show off paging.
+func NewBackend(profile config.Profile, approve Approver) (Backend, error) {
+	if u, err := url.Parse(profile.Url); err == nil && u.Scheme == FakeScheme {
+		fake, err := NewFakeBackend(Fixtures)
+		if err != nil {
+			return nil, err
+		}
+		if fail, err := strconv.Atoi(u.Query().Get("fail")); err == nil {
+			fake.FailNext(fail)
+		}
+		if size, err := strconv.Atoi(u.Query().Get("pagesize")); err == nil && size > 0 {
+			fake.pageSize = size
+		}
+		if latency, err := time.ParseDuration(u.Query().Get("latency")); err == nil {
+			fake.latency = latency
+		}
+		return fake, nil
+	}
+	return NewJiraData(profile, approve)
+}
@@ -7,52 +7,221 @@ import (
 	"github.com/andygrunwald/go-jira"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/evertras/bubble-table/table"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
 )
 
+const columnKeyStatus = "status" // issue status
+
+// a table of issues, streamed in a page at a time from fetch
 type BoardView struct {
-	jiraData JiraData
-	board    jira.Board
+	id       int
+	jiraData Backend
+	boardID  int                                    // whose columns the issues can be laid out in, 0 if none
+	title    string                                 // what we're listing, e.g. the board name
+	fetch    func(startAt int) (*IssuesPage, error) // where the issues come from
 	issues   []jira.Issue
+	total    int  // how many issues jira says there are
+	loading  bool // more pages on the way
 	width    int
+	table    table.Model
+}
+
+// a page of issues arrived for a view
+type issuesPageEvent struct {
+	view int
+	page *IssuesPage
+}
+
+// issues on a board
+func NewBoardView(jiraData Backend, board jira.Board, width int) BoardView {
+	return newBoardView(jiraData, board.ID, board.Name, width, func(startAt int) (*IssuesPage, error) {
+		return jiraData.GetIssuesForBoard(board, startAt)
+	})
+}
+
+// issues in a board's backlog
+func NewBacklogView(jiraData Backend, board jira.Board, width int) BoardView {
+	return newBoardView(jiraData, board.ID, board.Name+" (backlog)", width, func(startAt int) (*IssuesPage, error) {
+		return jiraData.GetBacklogForBoard(board, startAt)
+	})
+}
+
+// issues in a sprint
+func NewSprintView(jiraData Backend, sprint jira.Sprint, width int) BoardView {
+	return newBoardView(jiraData, sprint.OriginBoardID, sprint.Name, width, func(startAt int) (*IssuesPage, error) {
+		return jiraData.GetIssuesForSprint(sprint, startAt)
+	})
+}
+
+// issues matching some jql. the prompt already fetched the first page to
+// check the query, so that one isn't fetched again.
+func NewSearchView(jiraData Backend, jql string, first *IssuesPage, width int) BoardView {
+	return newBoardView(jiraData, 0, jql, width, func(startAt int) (*IssuesPage, error) {
+		if page := first; startAt == 0 && page != nil {
+			first = nil
+			return page, nil
+		}
+		return jiraData.SearchIssues(jql, startAt)
+	})
+}
+
+func newBoardView(jiraData Backend, boardID int, title string, width int, fetch func(startAt int) (*IssuesPage, error)) BoardView {
+	return BoardView{
+		id:       nextViewID(),
+		jiraData: jiraData,
+		boardID:  boardID,
+		title:    title,
+		fetch:    fetch,
+		issues:   make([]jira.Issue, 0),
+		loading:  true,
+		width:    width,
+		table:    table.New(issueColumns(nil)).Filtered(true).Focused(true).WithKeyMap(tableKeyMap()).WithTargetWidth(width),
+	}
 }
 
 func (b BoardView) Init() tea.Cmd {
-	return tea.Batch(
-		func() tea.Msg {
-			b.issues = b.jiraData.GetIssuesForBoard(b.board)
-			return nil
-		},
-	)
+	return b.fetchPage(0)
+}
+
+func (b BoardView) fetchPage(startAt int) tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		page, err := b.fetch(startAt)
+		return issuesPageEvent{view: b.id, page: page}, err
+	})
 }
 
 func (b BoardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		b.width = msg.Width
-	}
-	return b, nil
-}
+		b.table = b.table.WithTargetWidth(msg.Width).WithPageSize(tablePageSize(msg.Height))
 
-func (b BoardView) View() string {
-	columns := make([]table.Column, 0)
+	case KeysChangedMsg:
+		b.table = b.table.WithKeyMap(tableKeyMap())
 
-	issueWithLongestID := slices.MaxFunc(b.issues, func(a jira.Issue, b jira.Issue) int {
-		return cmp.Compare(len(a.ID), len(b.ID))
-	})
-	columns = append(columns, table.NewColumn(columnKeyID, "ID", len(issueWithLongestID.ID)))
-	columns = append(columns, table.NewFlexColumn(columnKeyName, "Name", 1))
+	case tea.KeyMsg:
+		if b.table.GetIsFilterInputFocused() {
+			break
+		}
+		if action, ok := keymap.Keys.Match(msg, keymap.Issues); ok {
+			if view, cmd, done := b.Do(action); done {
+				return view, cmd
+			}
+		}
+
+	case issuesPageEvent:
+		if msg.view != b.id {
+			break
+		}
+		b.issues = append(b.issues, msg.page.Issues...)
+		b.total = msg.page.Total
+		b.table = b.table.WithColumns(issueColumns(b.issues)).WithRows(b.rows())
+
+		// keep going until we have all of them
+		b.loading = !msg.page.IsLast()
+		if b.loading {
+			cmds = append(cmds, b.fetchPage(msg.page.StartAt+len(msg.page.Issues)))
+		}
+
+	// swap the row for the changed issue, leaving the cursor where it is
+	case IssueUpdatedMsg:
+		if i := slices.IndexFunc(b.issues, func(issue jira.Issue) bool { return issue.ID == msg.Issue.ID }); i >= 0 {
+			b.issues[i] = *msg.Issue
+			b.table = b.table.WithRows(b.rows())
+		}
+	}
+
+	var cmd tea.Cmd
+	b.table, cmd = updateTable(b.table, msg)
+	cmds = append(cmds, cmd)
+	return b, tea.Batch(cmds...)
+}
 
+func (b BoardView) rows() []table.Row {
 	rows := make([]table.Row, 0)
 	for _, issue := range b.issues {
 		rows = append(rows, IssueToTableRow(issue))
 	}
+	return rows
+}
+
+func (b BoardView) Title() string {
+	return b.title
+}
 
-	return table.New(columns).WithRows(rows).Filtered(true).Focused(true).WithTargetWidth(b.width).View()
+func (b BoardView) Progress() (int, int, bool) {
+	return len(b.issues), b.total, !b.loading
+}
+
+func (b BoardView) View() string {
+	return b.table.View()
+}
+
+// the ID column is as wide as the longest ID
+func issueColumns(issues []jira.Issue) []table.Column {
+	idWidth := 2
+	if len(issues) > 0 {
+		issueWithLongestID := slices.MaxFunc(issues, func(a jira.Issue, b jira.Issue) int {
+			return cmp.Compare(len(a.ID), len(b.ID))
+		})
+		idWidth = max(idWidth, len(issueWithLongestID.ID))
+	}
+	return []table.Column{
+		table.NewColumn(columnKeyID, "ID", idWidth),
+		table.NewFlexColumn(columnKeyName, "Name", 1),
+		table.NewColumn(columnKeyStatus, "Status", 14),
+	}
 }
 
 func IssueToTableRow(issue jira.Issue) table.Row {
 	return table.NewRow(table.RowData{
-		columnKeyID:   issue.ID,
-		columnKeyName: issue.Key,
+		columnKeyID:     issue.ID,
+		columnKeyName:   issue.Key,
+		columnKeyStatus: issueStatus(issue),
 	})
 }
+
+func issueStatus(issue jira.Issue) string {
+	if issue.Fields == nil || issue.Fields.Status == nil {
+		return ""
+	}
+	return issue.Fields.Status.Name
+}
+
+func (b BoardView) Do(action string) (tea.Model, tea.Cmd, bool) {
+	row := b.table.HighlightedRow()
+	switch action {
+	case "enter":
+		if row.Data == nil {
+			return b, nil, true
+		}
+		issueID := row.Data[columnKeyID].(string)
+		return b, func() tea.Msg { return OpenIssueMsg{IssueID: issueID} }, true
+	case "transition":
+		if row.Data == nil {
+			return b, nil, true
+		}
+		issueKey := row.Data[columnKeyName].(string)
+		return b, func() tea.Msg { return TransitionIssueMsg{IssueID: issueKey} }, true
+	case "new_issue":
+		boardID := b.boardID
+		return b, func() tea.Msg { return NewIssueMsg{BoardID: boardID} }, true
+	// search results aren't on a board, so there are no columns to lay
+	// them out in
+	case "layout":
+		if b.boardID == 0 {
+			return b, nil, false
+		}
+		open := OpenKanbanMsg{BoardID: b.boardID, Title: b.title, Fetch: b.fetch}
+		return b, func() tea.Msg { return open }, true
+	}
+	var done bool
+	b.table, done = doTable(b.table, action)
+	return b, nil, done
+}
+
+func (b BoardView) Capturing() bool {
+	return b.table.GetIsFilterInputFocused()
+}
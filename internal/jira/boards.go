@@ -6,6 +6,7 @@ import (
 	"github.com/andygrunwald/go-jira"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/evertras/bubble-table/table"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
 )
 
 const (
@@ -14,54 +15,142 @@ const (
 )
 
 type BoardsView struct {
-	jiraData JiraData
+	id       int
+	jiraData Backend
 	boards   []jira.Board
+	total    int  // how many boards jira says there are
+	loading  bool // more pages on the way
 	width    int
 	table    table.Model
 }
 
-type updatedBoardsEvent int
+// a page of boards arrived for a view
+type boardsPageEvent struct {
+	view int
+	page *jira.BoardsList
+}
+
+// the user picked a board
+type OpenBoardMsg struct {
+	Board jira.Board
+}
 
-func NewBoardsView(jiraData JiraData, width int) BoardsView {
+func NewBoardsView(jiraData Backend, width int) BoardsView {
 	columns := make([]table.Column, 0)
 	maxColumnKeyIDWidth := 4
 
 	columns = append(columns, table.NewColumn(columnKeyID, "ID", maxColumnKeyIDWidth+1))
 	columns = append(columns, table.NewFlexColumn(columnKeyName, "Name", 1))
 
-	table := table.New(columns).WithTargetWidth(width)
+	table := table.New(columns).Filtered(true).Focused(true).WithKeyMap(tableKeyMap()).WithTargetWidth(width)
 
 	return BoardsView{
+		id:       nextViewID(),
 		jiraData: jiraData,
 		boards:   make([]jira.Board, 0),
+		loading:  true,
 		width:    width,
 		table:    table,
 	}
 }
 
 func (b BoardsView) Init() tea.Cmd {
-	return func() tea.Msg {
-		b.boards = append(b.boards, b.jiraData.GetBoards().Values...)
-		slog.Debug("retrieving boards", "boards", b.boards)
-		return updatedBoardsEvent(len(b.boards))
-	}
+	return b.fetchPage(0)
+}
+
+func (b BoardsView) fetchPage(startAt int) tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		boards, err := b.jiraData.GetBoards(startAt)
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("retrieving boards", "startAt", startAt, "boards", boards.Values)
+		return boardsPageEvent{view: b.id, page: boards}, nil
+	})
 }
 
 func (b BoardsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	slog.Debug("update", "msg", msg)
+	cmds := make([]tea.Cmd, 0)
 
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
 		b.width = msg.Width
+		b.table = b.table.WithTargetWidth(msg.Width).WithPageSize(tablePageSize(msg.Height))
+
+	case KeysChangedMsg:
+		b.table = b.table.WithKeyMap(tableKeyMap())
+
+	case boardsPageEvent:
+		if msg.view != b.id {
+			break
+		}
+		slog.Debug("updated boards", "count", len(msg.page.Values))
+		b.boards = append(b.boards, msg.page.Values...)
+		b.total = msg.page.Total
+		rows := make([]table.Row, 0)
+		for _, board := range b.boards {
+			rows = append(rows, boardToTableRow(board))
+		}
+		b.table = b.table.WithRows(rows)
+
+		// keep going until jira says we're done
+		b.loading = !msg.page.IsLast && len(msg.page.Values) > 0
+		if b.loading {
+			cmds = append(cmds, b.fetchPage(msg.page.StartAt+len(msg.page.Values)))
+		}
+
+	case tea.KeyMsg:
+		if b.table.GetIsFilterInputFocused() {
+			break
+		}
+		if action, ok := keymap.Keys.Match(msg, keymap.Boards); ok {
+			if view, cmd, done := b.Do(action); done {
+				return view, cmd
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	b.table, cmd = updateTable(b.table, msg)
+	cmds = append(cmds, cmd)
+	return b, tea.Batch(cmds...)
+}
 
-	case updatedBoardsEvent:
-		slog.Debug("updated boards", "count", msg)
+func (b BoardsView) Do(action string) (tea.Model, tea.Cmd, bool) {
+	switch action {
+	case "enter":
+		board, ok := b.highlightedBoard()
+		if !ok {
+			return b, nil, true
+		}
+		return b, func() tea.Msg { return OpenBoardMsg{Board: board} }, true
+	// in the highlighted board's project
+	case "new_issue":
+		board, _ := b.highlightedBoard()
+		return b, func() tea.Msg { return NewIssueMsg{BoardID: board.ID} }, true
+	}
+	var done bool
+	b.table, done = doTable(b.table, action)
+	return b, nil, done
+}
 
-	default:
-		slog.Warn("unknown update type", "msg", msg)
+func (b BoardsView) highlightedBoard() (jira.Board, bool) {
+	row := b.table.HighlightedRow()
+	if row.Data == nil {
+		return jira.Board{}, false
 	}
-	return b, nil
+	for _, board := range b.boards {
+		if board.ID == row.Data[columnKeyID] {
+			return board, true
+		}
+	}
+	return jira.Board{}, false
+}
+
+func (b BoardsView) Progress() (int, int, bool) {
+	return len(b.boards), b.total, !b.loading
 }
 
 func (b BoardsView) View() string {
@@ -76,12 +165,7 @@ func (b BoardsView) View() string {
 	// 	maxColumnKeyIDWidth = len(strconv.Itoa(boardWithLongestID.ID))
 	// }
 
-	rows := make([]table.Row, 0)
-	for _, board := range b.boards {
-		rows = append(rows, boardToTableRow(board))
-	}
-	return b.table.WithRows(rows).Filtered(true).Focused(true).WithTargetWidth(b.width).View()
-	// return table.New(columns).WithRows(rows).Filtered(true).Focused(true).WithTargetWidth(b.width).View()
+	return b.table.View()
 }
 
 func boardToTableRow(board jira.Board) table.Row {
@@ -90,3 +174,7 @@ func boardToTableRow(board jira.Board) table.Row {
 		columnKeyName: board.Name,
 	})
 }
+
+func (b BoardsView) Capturing() bool {
+	return b.table.GetIsFilterInputFocused()
+}
@@ -0,0 +1,110 @@
+package jira
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/pkg/m2j"
+)
+
+// the editor closed, with whatever was saved
+type commentEditedEvent struct {
+	view      int
+	commentID string // empty for a new comment
+	body      string // markdown
+	err       error
+}
+
+// $VISUAL or $EDITOR, which may carry arguments (e.g. `code --wait`)
+func editorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
+// hand the terminal over to the editor on a markdown file holding initial,
+// and get back what was written once it exits
+func (i IssueView) editComment(commentID string, initial string) tea.Cmd {
+	if initial != "" && !strings.HasSuffix(initial, "\n") {
+		initial += "\n"
+	}
+	file, err := os.CreateTemp("", "go-jira-tui-*.md")
+	if err == nil {
+		_, err = file.WriteString(initial)
+		file.Close()
+	}
+	if err != nil {
+		return func() tea.Msg {
+			return commentEditedEvent{view: i.id, commentID: commentID, err: fmt.Errorf("making a file to edit: %w", err)}
+		}
+	}
+
+	view, path := i.id, file.Name()
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return commentEditedEvent{view: view, commentID: commentID, err: fmt.Errorf("running the editor: %w", err)}
+		}
+		body, err := os.ReadFile(path)
+		return commentEditedEvent{view: view, commentID: commentID, body: string(body), err: err}
+	})
+}
+
+// send what was written to jira as wiki markup, then fetch the issue again
+// so every view showing it catches up
+func (i IssueView) saveComment(commentID string, body string) tea.Cmd {
+	issueID, markup := i.issue.Key, m2j.MDToJira(strings.TrimSpace(body))
+	return change(func() (tea.Msg, error) {
+		var err error
+		if commentID == "" {
+			_, err = i.jiraData.AddComment(issueID, markup)
+		} else {
+			_, err = i.jiraData.UpdateComment(issueID, commentID, markup)
+		}
+		return nil, err
+	}, i.refetch(issueID))
+}
+
+func (i IssueView) deleteComment(commentID string) tea.Cmd {
+	issueID := i.issue.Key
+	return change(func() (tea.Msg, error) {
+		return nil, i.jiraData.DeleteComment(issueID, commentID)
+	}, i.refetch(issueID))
+}
+
+// fetch the issue again after changing it, so every view showing it catches up
+func (i IssueView) refetch(issueID string) tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		issue, err := i.jiraData.GetIssue(issueID)
+		return IssueUpdatedMsg{Issue: issue}, err
+	})
+}
+
+func (i IssueView) comments() []*jira.Comment {
+	if i.issue == nil || i.issue.Fields.Comments == nil {
+		return nil
+	}
+	return i.issue.Fields.Comments.Comments
+}
+
+// the comment that's picked, if it's ours to change
+func (i IssueView) ownSelectedComment() (*jira.Comment, bool) {
+	comments := i.comments()
+	if i.selected < 0 || i.selected >= len(comments) {
+		return nil, false
+	}
+	comment, self := comments[i.selected], i.jiraData.Self()
+	if comment.Author.AccountID != self.AccountID || comment.Author.Name != self.Name {
+		return comment, false
+	}
+	return comment, true
+}
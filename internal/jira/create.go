@@ -0,0 +1,481 @@
+package jira
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// fields that come first on the form, in this order. the rest follow,
+// required ones first.
+var leadingFormFields = []string{"summary", "description", "priority", "components"}
+
+// a form for a new issue. the project and issue type decide which fields
+// show up, going by what createmeta says their screen has.
+type IssueForm struct {
+	id             int
+	jiraData       Backend
+	boardID        int                 // whose project is picked to start with, 0 if none
+	defaultProject string              // the board's project key, once we know it
+	configured     bool                // the board's project is known, or there's no board
+	projects       []CreateMetaProject // nil until jira says
+	fields         []formField         // project, issue type, then whatever the screen has
+	focus          int
+	suggestSeq     int    // the last pause in typing, so older suggestions are dropped
+	err            string // what jira didn't like that isn't about one field
+	busy           bool   // waiting on jira to make the issue
+	loading        string // the issue types or fields being fetched, as they're shown
+	accentColor    lipgloss.Color
+	width          int
+	height         int
+}
+
+// createmeta arrived for a form
+type createMetaEvent struct {
+	view     int
+	projects []CreateMetaProject
+}
+
+// a project's issue types arrived for a form
+type createIssueTypesEvent struct {
+	view       int
+	project    string
+	issueTypes []CreateMetaIssueType
+}
+
+// the fields an issue type asks for arrived for a form
+type createFieldsEvent struct {
+	view      int
+	project   string
+	issueType string
+	fields    map[string]FieldMeta
+}
+
+// jira wouldn't make the issue, and said why
+type createRejectedEvent struct {
+	view int
+	err  error
+}
+
+// the user wants to make an issue, in the project of the board they're on
+type NewIssueMsg struct {
+	BoardID int // 0 if there's no board to go by
+}
+
+// an issue was made, show it instead of the form
+type IssueCreatedMsg struct {
+	IssueID string
+}
+
+// the view on top is done, go back to the one underneath
+type CloseMsg struct{}
+
+func NewIssueForm(jiraData Backend, boardID int, accentColor lipgloss.Color, width int, height int) IssueForm {
+	return IssueForm{
+		id:          nextViewID(),
+		jiraData:    jiraData,
+		boardID:     boardID,
+		configured:  boardID == 0,
+		accentColor: accentColor,
+		width:       width,
+		height:      height,
+	}
+}
+
+func (f IssueForm) Init() tea.Cmd {
+	cmds := []tea.Cmd{request(func() (tea.Msg, error) {
+		projects, err := f.jiraData.GetCreateMeta()
+		return createMetaEvent{view: f.id, projects: projects}, err
+	})}
+	if f.boardID != 0 {
+		cmds = append(cmds, request(func() (tea.Msg, error) {
+			configuration, err := f.jiraData.GetBoardConfiguration(f.boardID)
+			return boardConfigurationEvent{view: f.id, configuration: configuration}, err
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (f IssueForm) create(values map[string]any) tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		issue, err := f.jiraData.CreateIssue(values)
+		if reason, ok := rejected(err); ok {
+			return createRejectedEvent{view: f.id, err: reason}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return IssueCreatedMsg{IssueID: issue.Key}, nil
+	})
+}
+
+func (f IssueForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		f.width = msg.Width
+		f.height = msg.Height
+		f.resizeInputs()
+
+	case AccentColorMsg:
+		f.accentColor = msg.Color
+
+	case createMetaEvent:
+		if msg.view == f.id {
+			f.projects = msg.projects
+			return f, f.build()
+		}
+
+	// kept with the project, so going back to it needn't fetch them again
+	case createIssueTypesEvent:
+		if msg.view != f.id {
+			break
+		}
+		if i := slices.IndexFunc(f.projects, func(p CreateMetaProject) bool { return p.Key == msg.project }); i >= 0 {
+			f.projects[i].IssueTypes = msg.issueTypes
+			// none is an answer too, so it's not asked for again
+			if f.projects[i].IssueTypes == nil {
+				f.projects[i].IssueTypes = make([]CreateMetaIssueType, 0)
+			}
+		}
+		f.loading = ""
+		return f, f.rebuild()
+
+	case createFieldsEvent:
+		if msg.view != f.id {
+			break
+		}
+		if i := slices.IndexFunc(f.projects, func(p CreateMetaProject) bool { return p.Key == msg.project }); i >= 0 {
+			issueTypes := f.projects[i].IssueTypes
+			if j := slices.IndexFunc(issueTypes, func(t CreateMetaIssueType) bool { return t.ID == msg.issueType }); j >= 0 {
+				issueTypes[j].Fields = msg.fields
+				if issueTypes[j].Fields == nil {
+					issueTypes[j].Fields = make(map[string]FieldMeta)
+				}
+			}
+		}
+		f.loading = ""
+		return f, f.rebuild()
+
+	case boardConfigurationEvent:
+		if msg.view == f.id {
+			if msg.configuration.Location.Type == "project" {
+				f.defaultProject = msg.configuration.Location.Key
+			}
+			f.configured = true
+			return f, f.build()
+		}
+
+	case createRejectedEvent:
+		if msg.view == f.id {
+			f.busy = false
+			f.showErrors(msg.err)
+		}
+
+	case suggestTickEvent:
+		if msg.view == f.id && msg.seq == f.suggestSeq && f.fields != nil {
+			return f, fetchSuggestions(f.jiraData, f.id, msg.seq, f.fields[f.focus], UserScope{Project: f.projects[f.fields[0].choice].Key})
+		}
+
+	case suggestionsEvent:
+		if msg.view == f.id && msg.seq == f.suggestSeq && f.fields != nil {
+			f.fields[f.focus].suggest(msg.users, msg.labels)
+		}
+
+	case tea.KeyMsg:
+		if f.fields == nil || f.busy {
+			if key.Matches(msg, keymap.Keys.Back) {
+				return f, func() tea.Msg { return CloseMsg{} }
+			}
+			break
+		}
+		field := &f.fields[f.focus]
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			return f, func() tea.Msg { return CloseMsg{} }
+		case key.Matches(msg, keymap.Keys.Submit):
+			return f.submit()
+		// suggestions showing under a field take tab and ↑/↓, and enter
+		// takes the one under the cursor
+		case field.completing():
+			if key.Matches(msg, keymap.Keys.Enter) {
+				field.complete()
+				return f, nil
+			}
+		case key.Matches(msg, keymap.Keys.NextField), msg.Type == tea.KeyDown:
+			return f, f.focusField(f.focus + 1)
+		case key.Matches(msg, keymap.Keys.PrevField), msg.Type == tea.KeyUp:
+			return f, f.focusField(f.focus - 1)
+		// enter moves along, and sends the form off from the last field
+		case key.Matches(msg, keymap.Keys.Enter):
+			if f.focus == len(f.fields)-1 {
+				return f.submit()
+			}
+			return f, f.focusField(f.focus + 1)
+		}
+
+		choice, query := field.choice, field.query()
+		cmd := field.update(msg)
+		field.err = ""
+		switch {
+		// a different project or issue type has a different screen
+		case (field.id == "project" || field.id == "issuetype") && field.choice != choice:
+			return f, f.rebuild()
+		case field.suggests() && field.query() != query:
+			f.suggestSeq++
+			return f, tea.Batch(cmd, waitToSuggest(f.id, f.suggestSeq))
+		}
+		return f, cmd
+	}
+
+	return f, nil
+}
+
+// once we know what can be made and where, lay out the form for the
+// board's project, or the first one
+func (f *IssueForm) build() tea.Cmd {
+	if f.projects == nil || !f.configured || f.fields != nil {
+		return nil
+	}
+	if len(f.projects) == 0 {
+		f.err = "there aren't any projects you can make issues in"
+		return nil
+	}
+	projects := make([]AllowedValue, 0, len(f.projects))
+	for _, project := range f.projects {
+		projects = append(projects, AllowedValue{ID: project.ID, Name: fmt.Sprintf("%s (%s)", project.Name, project.Key)})
+	}
+	project := newSelectField("project", FieldMeta{Required: true, Name: "Project", AllowedValues: projects})
+	project.choice = max(slices.IndexFunc(f.projects, func(p CreateMetaProject) bool { return p.Key == f.defaultProject }), 0)
+	issueType := newSelectField("issuetype", FieldMeta{Required: true, Name: "Issue type"})
+	f.fields = []formField{project, issueType}
+	return f.rebuild()
+}
+
+// swap in the fields for whatever project and issue type are picked,
+// keeping anything already filled in that's still on the screen
+func (f *IssueForm) rebuild() tea.Cmd {
+	project := f.projects[f.fields[0].choice]
+	if project.IssueTypes == nil {
+		projectKey := project.Key
+		return f.fetch(projectKey+"'s issue types", func() (tea.Msg, error) {
+			issueTypes, err := f.jiraData.GetCreateIssueTypes(projectKey)
+			return createIssueTypesEvent{view: f.id, project: projectKey, issueTypes: issueTypes}, err
+		})
+	}
+	issueTypes := make([]AllowedValue, 0, len(project.IssueTypes))
+	for _, issueType := range project.IssueTypes {
+		issueTypes = append(issueTypes, AllowedValue{ID: issueType.ID, Name: issueType.Name})
+	}
+	// stick with the same issue type in another project, if it has one
+	previous := f.fields[1]
+	f.fields[1].meta.AllowedValues = issueTypes
+	f.fields[1].choice = 0
+	f.fields[1].carryOver(previous)
+	f.fields[1].choice = max(f.fields[1].choice, 0)
+	if len(issueTypes) == 0 {
+		f.fields[1].choice = -1
+	}
+
+	if f.fields[1].choice >= 0 && project.IssueTypes[f.fields[1].choice].Fields == nil {
+		projectKey, issueType := project.Key, project.IssueTypes[f.fields[1].choice]
+		return f.fetch(fmt.Sprintf("the fields for a %s in %s", issueType.Name, projectKey), func() (tea.Msg, error) {
+			fields, err := f.jiraData.GetCreateFields(projectKey, issueType.ID)
+			return createFieldsEvent{view: f.id, project: projectKey, issueType: issueType.ID, fields: fields}, err
+		})
+	}
+
+	old := f.fields[2:]
+	f.fields = f.fields[:2]
+	if f.fields[1].choice >= 0 {
+		screen := project.IssueTypes[f.fields[1].choice].Fields
+		for _, id := range formFieldOrder(screen) {
+			field, ok := newFormField(id, screen[id])
+			if !ok {
+				continue
+			}
+			if i := slices.IndexFunc(old, func(o formField) bool { return o.id == id }); i >= 0 {
+				field.carryOver(old[i])
+			}
+			f.fields = append(f.fields, field)
+		}
+	}
+	f.err = ""
+	f.resizeInputs()
+	return f.focusField(min(f.focus, len(f.fields)-1))
+}
+
+// fetch what the picked project or issue type is missing, unless it's
+// already on its way
+func (f *IssueForm) fetch(what string, fetch func() (tea.Msg, error)) tea.Cmd {
+	if f.loading == what {
+		return nil
+	}
+	f.loading = what
+	return request(fetch)
+}
+
+// the screen's fields minus the project and issue type, which are already
+// on the form, in the order they're shown
+func formFieldOrder(screen map[string]FieldMeta) []string {
+	ids := slices.Collect(maps.Keys(screen))
+	ids = slices.DeleteFunc(ids, func(id string) bool { return id == "project" || id == "issuetype" })
+	rank := func(id string) int {
+		if i := slices.Index(leadingFormFields, id); i >= 0 {
+			return i
+		}
+		if screen[id].Required {
+			return len(leadingFormFields)
+		}
+		return len(leadingFormFields) + 1
+	}
+	slices.SortFunc(ids, func(a string, b string) int {
+		if rank(a) != rank(b) {
+			return rank(a) - rank(b)
+		}
+		return strings.Compare(screen[a].Name, screen[b].Name)
+	})
+	return ids
+}
+
+func (f *IssueForm) focusField(focus int) tea.Cmd {
+	if len(f.fields) == 0 {
+		return nil
+	}
+	f.focus = (focus + len(f.fields)) % len(f.fields)
+	var cmd tea.Cmd
+	for i := range f.fields {
+		if i == f.focus {
+			cmd = f.fields[i].focus()
+		} else {
+			f.fields[i].blur()
+		}
+	}
+	return cmd
+}
+
+func (f *IssueForm) resizeInputs() {
+	for i := range f.fields {
+		f.fields[i].input.Width = max(f.width-f.labelWidth()-4, 1)
+	}
+}
+
+// gather up every field's value, and send them off if they all make sense
+func (f IssueForm) submit() (tea.Model, tea.Cmd) {
+	// the form's still catching up with the project or issue type
+	if f.loading != "" {
+		return f, nil
+	}
+	project, issueType := f.projects[f.fields[0].choice], f.fields[1]
+	if issueType.choice < 0 {
+		f.fields[1].err = "there's nothing you can make in this project"
+		return f, nil
+	}
+	values := map[string]any{
+		"project":   map[string]string{"id": project.ID},
+		"issuetype": map[string]string{"id": issueType.meta.AllowedValues[issueType.choice].ID},
+	}
+	ok := true
+	for i := range f.fields[2:] {
+		field := &f.fields[i+2]
+		value, err := field.value()
+		if err != nil {
+			field.err = err.Error()
+			ok = false
+			continue
+		}
+		if value != nil {
+			values[field.id] = value
+		}
+	}
+	if !ok {
+		return f, nil
+	}
+	f.busy = true
+	f.err = ""
+	return f, f.create(values)
+}
+
+// put each of jira's complaints next to its field, and anything else
+// underneath the form
+func (f *IssueForm) showErrors(err error) {
+	problems, others := fieldErrors(err)
+	for id, problem := range problems {
+		i := slices.IndexFunc(f.fields, func(field formField) bool { return field.id == id })
+		if i < 0 {
+			others = append(others, fmt.Sprintf("%s: %s", id, problem))
+			continue
+		}
+		f.fields[i].err = problem
+	}
+	slices.Sort(others)
+	f.err = strings.Join(others, "\n")
+	// start with the first field that needs fixing
+	if i := slices.IndexFunc(f.fields, func(field formField) bool { return field.err != "" }); i >= 0 {
+		f.focusField(i)
+	}
+}
+
+// a field being typed in takes every key, esc included, so nothing typed
+// goes elsewhere. otherwise the app's keys work, so a fetch that failed can
+// be retried or dismissed.
+func (f IssueForm) Capturing() bool {
+	if f.fields == nil || f.busy {
+		return false
+	}
+	kind := f.fields[f.focus].kind
+	return kind == textField || kind == userField
+}
+
+// room for the longest label and the required marker
+func (f IssueForm) labelWidth() int {
+	width := 0
+	for _, field := range f.fields {
+		width = max(width, lipgloss.Width(field.meta.Name)+1)
+	}
+	return width
+}
+
+func (f IssueForm) View() string {
+	switch {
+	case f.err != "" && f.fields == nil:
+		return jqlErrorStyle.Width(f.width).Render(f.err)
+	case f.fields == nil:
+		return "loading create metadata..."
+	}
+
+	// every field's lines, remembering where the focused one ends so it
+	// can be scrolled into view
+	labelWidth := f.labelWidth()
+	lines := make([]string, 0, len(f.fields))
+	focusBottom := 0
+	for i, field := range f.fields {
+		lines = append(lines, strings.Split(field.view(labelWidth, f.accentColor, f.width), "\n")...)
+		if i == f.focus {
+			focusBottom = len(lines)
+		}
+	}
+
+	footer := make([]string, 0, 2)
+	switch {
+	case f.busy:
+		footer = append(footer, jqlHintStyle.Render("creating..."))
+	case f.loading != "":
+		footer = append(footer, jqlHintStyle.Render("loading "+f.loading+"..."))
+	case f.err != "":
+		footer = append(footer, jqlErrorStyle.Width(f.width).Render(f.err))
+	}
+	footer = append(footer, jqlHintStyle.Render(strings.Join([]string{keymap.Does("create", keymap.Keys.Submit), keymap.Does("move", keymap.Keys.NextField, keymap.Keys.PrevField), "←/→: pick", keymap.Does("toggle", keymap.Keys.Toggle), keymap.Does("cancel", keymap.Keys.Back)}, " • ")))
+	footerHeight := lipgloss.Height(strings.Join(footer, "\n"))
+
+	height := max(f.height-footerHeight, 1)
+	top := min(max(focusBottom-height, 0), max(len(lines)-1, 0))
+	lines = lines[top:min(top+height, len(lines))]
+	return lipgloss.JoinVertical(lipgloss.Left, strings.Join(lines, "\n"), strings.Join(footer, "\n"))
+}
@@ -0,0 +1,208 @@
+package jira
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// fields that can be changed from the details pane, in the order they're
+// shown there. story points go last, found by name since their id differs
+// between instances.
+var editableFields = []string{"assignee", "priority", "labels", "components", "fixVersions", "duedate"}
+
+// what the issue's fields can be changed to arrived for a view
+type editMetaEvent struct {
+	view   int
+	fields map[string]FieldMeta
+}
+
+// jira wouldn't take the change, and said why
+type fieldRejectedEvent struct {
+	view int
+	err  error
+}
+
+func (i IssueView) fetchEditMeta() tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		fields, err := i.jiraData.GetEditMeta(i.issueID)
+		return editMetaEvent{view: i.id, fields: fields}, err
+	})
+}
+
+// send the field being edited to jira, then fetch the issue again so every
+// view showing it catches up
+func (i IssueView) saveField(value any) tea.Cmd {
+	issueID, fields := i.issue.Key, map[string]any{i.editor.id: value}
+	return change(func() (tea.Msg, error) {
+		err := i.jiraData.UpdateIssue(issueID, fields)
+		if reason, ok := rejected(err); ok {
+			return fieldRejectedEvent{view: i.id, err: reason}, nil
+		}
+		return nil, err
+	}, i.refetch(issueID))
+}
+
+// hand the issue to whoever we're signed in as, then fetch it again like a
+// saved field
+func (i IssueView) assignToSelf() tea.Cmd {
+	issueID, self := i.issue.Key, i.jiraData.Self()
+	assignee := map[string]string{"accountId": self.AccountID}
+	// jira server goes by username
+	if self.AccountID == "" {
+		assignee = map[string]string{"name": self.Name}
+	}
+	return change(func() (tea.Msg, error) {
+		return nil, i.jiraData.UpdateIssue(issueID, map[string]any{"assignee": assignee})
+	}, i.refetch(issueID))
+}
+
+// the fields jira lets us change that the details pane knows how to show
+func (i IssueView) editableFieldIDs() []string {
+	ids := slices.DeleteFunc(slices.Clone(editableFields), func(id string) bool {
+		_, ok := i.editMeta[id]
+		return !ok
+	})
+	for _, name := range storyPointsFieldNames {
+		for id, meta := range i.editMeta {
+			if meta.Name == name {
+				return append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// the field picked in the details pane, if any
+func (i IssueView) pickedField() string {
+	ids := i.editableFieldIDs()
+	if !i.fieldMode || i.fieldCursor >= len(ids) {
+		return ""
+	}
+	return ids[i.fieldCursor]
+}
+
+// start picking a field to change. what can be changed is only asked for
+// the first time.
+func (i IssueView) startFieldMode() (tea.Model, tea.Cmd) {
+	i.fieldMode = true
+	i.fieldCursor = 0
+	if i.editMeta == nil {
+		i.setNotice("fetching editable fields...")
+		return i, i.fetchEditMeta()
+	}
+	i.setNotice(fieldModeHint())
+	return i, nil
+}
+
+// the keys for picking a field to change, as they're bound
+func fieldModeHint() string {
+	return strings.Join([]string{keymap.Does("pick a field", keymap.Keys.Up, keymap.Keys.Down), keymap.Does("edit", keymap.Keys.Enter), keymap.Does("done", keymap.Keys.Back)}, " • ")
+}
+
+// the keys for the field being changed
+func editingHint() string {
+	return strings.Join([]string{keymap.Does("save", keymap.Keys.Enter), keymap.Does("cancel", keymap.Keys.Back)}, " • ")
+}
+
+func (i IssueView) updateFieldMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	ids := i.editableFieldIDs()
+	switch {
+	case key.Matches(msg, keymap.Keys.Back):
+		i.fieldMode = false
+		i.setNotice("")
+	case key.Matches(msg, keymap.Keys.Up):
+		i.fieldCursor = max(i.fieldCursor-1, 0)
+		i.layout()
+	case key.Matches(msg, keymap.Keys.Down):
+		i.fieldCursor = min(i.fieldCursor+1, max(len(ids)-1, 0))
+		i.layout()
+	case key.Matches(msg, keymap.Keys.Enter) && i.fieldCursor < len(ids):
+		return i.startEditing(ids[i.fieldCursor])
+	}
+	return i, nil
+}
+
+// swap the picked field's value for an editor holding it
+func (i IssueView) startEditing(id string) (tea.Model, tea.Cmd) {
+	editor, ok := newFormField(id, i.editMeta[id])
+	if !ok {
+		i.setNotice("that field can't be changed from here")
+		return i, nil
+	}
+	editor.fill(i.currentValue(id))
+	editor.input.Width = detailsWidth - 6
+	cmd := editor.focus()
+	i.editor, i.editing = editor, true
+	hint := editingHint()
+	if editor.suggests() {
+		hint += " • " + keymap.Does("complete", keymap.Keys.NextField)
+	}
+	i.setNotice(hint)
+	return i, cmd
+}
+
+// the field as jira sent it, e.g. {"id": "3", "name": "Medium"}
+func (i IssueView) currentValue(id string) any {
+	raw, err := json.Marshal(i.issue.Fields)
+	if err != nil {
+		return nil
+	}
+	fields := make(map[string]any)
+	if json.Unmarshal(raw, &fields) != nil {
+		return nil
+	}
+	return fields[id]
+}
+
+func (i IssueView) updateEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if i.saving {
+		return i, nil
+	}
+	switch {
+	case key.Matches(msg, keymap.Keys.Back):
+		i.editing = false
+		i.setNotice(fieldModeHint())
+		return i, nil
+	case key.Matches(msg, keymap.Keys.Enter):
+		value, err := i.editor.value()
+		if err != nil {
+			i.editor.err = err.Error()
+			i.layout()
+			return i, nil
+		}
+		i.saving = true
+		i.editor.err = ""
+		i.setNotice("saving " + strings.ToLower(i.editor.meta.Name) + "...")
+		return i, i.saveField(value)
+	}
+
+	query := i.editor.query()
+	cmd := i.editor.update(msg)
+	i.editor.err = ""
+	if i.editor.suggests() && i.editor.query() != query {
+		i.suggestSeq++
+		cmd = tea.Batch(cmd, waitToSuggest(i.id, i.suggestSeq))
+	}
+	i.layout()
+	return i, cmd
+}
+
+// jira's complaint goes under the field, whatever it's about
+func (i *IssueView) rejectField(err error) {
+	i.saving = false
+	problems, others := fieldErrors(err)
+	if problem, ok := problems[i.editor.id]; ok {
+		others = append([]string{problem}, others...)
+	} else {
+		for _, problem := range problems {
+			others = append(others, problem)
+		}
+	}
+	i.editor.err = strings.Join(others, "\n")
+	i.setNotice(editingHint())
+}
@@ -0,0 +1,105 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/andygrunwald/go-jira"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// a request to jira that didn't work out, with enough detail to tell the
+// user why
+type RequestError struct {
+	Op         string // what we were doing, e.g. "fetching boards"
+	StatusCode int    // 0 if we never got a response
+	RetryAfter string // Retry-After header, if jira rate limited us
+	Err        error  // go-jira's error, which carries the response body
+}
+
+func newRequestError(op string, resp *jira.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	reqErr := &RequestError{Op: op, Err: err}
+	if resp != nil {
+		reqErr.StatusCode = resp.StatusCode
+		reqErr.RetryAfter = resp.Header.Get("Retry-After")
+	}
+	return reqErr
+}
+
+func (e *RequestError) Error() string {
+	msg := e.Op
+	if e.StatusCode != 0 {
+		msg = fmt.Sprintf("%s: %d %s", msg, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	msg = fmt.Sprintf("%s: %s", msg, e.Err)
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		msg += " (is your token expired?)"
+	case http.StatusTooManyRequests:
+		if e.RetryAfter != "" {
+			msg += fmt.Sprintf(" (retry after %ss)", e.RetryAfter)
+		}
+	}
+	return msg
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// a command failed. the model shows Err and can run Retry to try again.
+type ErrorMsg struct {
+	Err   error
+	Retry tea.Cmd
+}
+
+// wrap fetch in a command that turns any error into an ErrorMsg whose Retry
+// runs the same fetch again
+func request(fetch func() (tea.Msg, error)) tea.Cmd {
+	var cmd tea.Cmd
+	cmd = func() tea.Msg {
+		msg, err := fetch()
+		if err != nil {
+			slog.Error("request failed", "err", err)
+			return ErrorMsg{Err: err, Retry: cmd}
+		}
+		return msg
+	}
+	return cmd
+}
+
+// like request, but for a change to jira, which mustn't be sent twice: send
+// it, then run then, e.g. to fetch what changed. once send has worked, only
+// then is retried if it fails. send can give a msg to stop there instead,
+// e.g. when jira rejected the change.
+func change(send func() (tea.Msg, error), then tea.Cmd) tea.Cmd {
+	var cmd tea.Cmd
+	cmd = func() tea.Msg {
+		msg, err := send()
+		if err != nil {
+			slog.Error("request failed", "err", err)
+			return ErrorMsg{Err: err, Retry: cmd}
+		}
+		if msg != nil {
+			return msg
+		}
+		return then()
+	}
+	return cmd
+}
+
+// a 400 is jira saying what's wrong with what was sent, which is for the user
+// to fix rather than retry. rejected gives jira's reason if err is one.
+func rejected(err error) (error, bool) {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusBadRequest {
+		return reqErr.Err, true
+	}
+	return nil, false
+}
@@ -0,0 +1,67 @@
+package jira
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// a change that worked isn't sent again when what comes after it fails
+func TestChangeRetries(t *testing.T) {
+	failed := errors.New("503 Service Unavailable")
+	tests := []struct {
+		name      string
+		sendFails bool
+		thenFails bool
+		sends     int // after a retry
+		thens     int
+	}{
+		{"send fails", true, false, 2, 1},
+		{"then fails", false, true, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sends, thens int
+			sendFails, thenFails := tt.sendFails, tt.thenFails
+			cmd := change(func() (tea.Msg, error) {
+				sends++
+				if sendFails {
+					sendFails = false
+					return nil, failed
+				}
+				return nil, nil
+			}, request(func() (tea.Msg, error) {
+				thens++
+				if thenFails {
+					thenFails = false
+					return nil, failed
+				}
+				return "done", nil
+			}))
+			msg, ok := cmd().(ErrorMsg)
+			if !ok {
+				t.Fatalf("got %v, want an ErrorMsg", msg)
+			}
+			if got := msg.Retry(); got != "done" {
+				t.Errorf("retrying got %v, want done", got)
+			}
+			if sends != tt.sends || thens != tt.thens {
+				t.Errorf("sent %d times and ran then %d times, want %d and %d", sends, thens, tt.sends, tt.thens)
+			}
+		})
+	}
+}
+
+// a msg from send, like jira rejecting the change, is the end of it
+func TestChangeStops(t *testing.T) {
+	cmd := change(func() (tea.Msg, error) {
+		return "rejected", nil
+	}, func() tea.Msg {
+		t.Error("ran then after send gave a msg")
+		return nil
+	})
+	if got := cmd(); got != "rejected" {
+		t.Errorf("got %v, want rejected", got)
+	}
+}
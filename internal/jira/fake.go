@@ -0,0 +1,985 @@
+package jira
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// fixtures bundled with the binary, so `url: fake://` works anywhere
+//
+//go:embed fixtures/*.json
+var embeddedFixtures embed.FS
+
+// the bundled fixtures, rooted so they can be handed to NewFakeBackend
+var Fixtures, _ = fs.Sub(embeddedFixtures, "fixtures")
+
+// in-memory backend seeded from fixture files. safe to share between the
+// views since bubbletea runs commands concurrently.
+type FakeBackend struct {
+	mu sync.Mutex
+
+	user    jira.User
+	boards  []jira.Board
+	sprints []jira.Sprint
+	issues  []jira.Issue
+	fields  []jira.Field
+
+	filters        []jira.Filter                   // saved filters, which boards are built on
+	configurations []jira.BoardConfiguration       // which filter each board uses
+	transitions    []Transition                    // the workflow; any issue can take any of these to a different status
+	createMeta     []CreateMetaProject             // what new issues can be made with
+	editMeta       map[string]map[string]FieldMeta // what can be changed, by project key
+	users          []jira.User                     // everyone who can be assigned
+	lastCommentID  int                             // new comments get ids after this
+	lastWorklogID  int                             // same for worklogs
+
+	failNext int           // status code the next request fails with, if any
+	pageSize int           // how many results each page holds
+	latency  time.Duration // how long each request takes
+}
+
+// read user.json, boards.json, sprints.json, issues.json, fields.json,
+// filters.json, configurations.json, transitions.json, createmeta.json,
+// editmeta.json and users.json out of fixtures
+func NewFakeBackend(fixtures fs.FS) (*FakeBackend, error) {
+	f := &FakeBackend{pageSize: pageSize}
+	boards := jira.BoardsList{}
+	createMeta := struct {
+		Projects []CreateMetaProject `json:"projects"`
+	}{}
+
+	for name, into := range map[string]any{
+		"user.json":    &f.user,
+		"boards.json":  &boards,
+		"sprints.json": &f.sprints,
+		"issues.json":  &f.issues,
+		"fields.json":  &f.fields,
+
+		"filters.json":        &f.filters,
+		"configurations.json": &f.configurations,
+		"transitions.json":    &f.transitions,
+		"createmeta.json":     &createMeta,
+		"editmeta.json":       &f.editMeta,
+		"users.json":          &f.users,
+	} {
+		if err := readFixture(fixtures, name, into); err != nil {
+			return nil, err
+		}
+	}
+	f.boards = boards.Values
+	f.createMeta = createMeta.Projects
+	for _, issue := range f.issues {
+		if issue.Fields.Comments == nil {
+			continue
+		}
+		for _, comment := range issue.Fields.Comments.Comments {
+			id, _ := strconv.Atoi(comment.ID)
+			f.lastCommentID = max(f.lastCommentID, id)
+		}
+	}
+	for _, issue := range f.issues {
+		if issue.Fields.Worklog == nil {
+			continue
+		}
+		for _, worklog := range issue.Fields.Worklog.Worklogs {
+			id, _ := strconv.Atoi(worklog.ID)
+			f.lastWorklogID = max(f.lastWorklogID, id)
+		}
+	}
+
+	return f, nil
+}
+
+func readFixture(fixtures fs.FS, name string, into any) error {
+	raw, err := fs.ReadFile(fixtures, name)
+	if err != nil {
+		return fmt.Errorf("reading fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(raw, into); err != nil {
+		return fmt.Errorf("parsing fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+// issues hold their fields by pointer, so they're copied on the way out to
+// keep changes here from reaching into what the views hold
+func cloneIssue(issue jira.Issue) (jira.Issue, error) {
+	copied, err := cloneFixture(issue)
+	if err != nil {
+		return jira.Issue{}, fmt.Errorf("copying fixture issue %s: %w", issue.Key, err)
+	}
+	return copied, nil
+}
+
+// fixtures holding maps and slices are copied the same way
+func cloneFixture[T any](fixture T) (T, error) {
+	var copied T
+	raw, err := json.Marshal(fixture)
+	if err != nil {
+		return copied, fmt.Errorf("copying fixture: %w", err)
+	}
+	if err := json.Unmarshal(raw, &copied); err != nil {
+		return copied, fmt.Errorf("copying fixture: %w", err)
+	}
+	return copied, nil
+}
+
+// make the next request fail with statusCode, to see how the UI copes
+func (f *FakeBackend) FailNext(statusCode int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = statusCode
+}
+
+// must hold f.mu. pretends to be a network round trip, which might fail.
+func (f *FakeBackend) takeFailure(op string) error {
+	time.Sleep(f.latency)
+	if f.failNext == 0 {
+		return nil
+	}
+	statusCode := f.failNext
+	f.failNext = 0
+	return fakeError(op, statusCode, fmt.Errorf("fake failure"))
+}
+
+func (f *FakeBackend) Self() *jira.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user := f.user
+	return &user
+}
+
+func (f *FakeBackend) Host() string {
+	return "offline"
+}
+
+// the [startAt, startAt+pageSize) window of a list of length total
+func (f *FakeBackend) window(startAt int, total int) (int, int) {
+	start := min(max(startAt, 0), total)
+	return start, min(start+f.pageSize, total)
+}
+
+func (f *FakeBackend) GetBoards(startAt int) (*jira.BoardsList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure("fetching boards"); err != nil {
+		return nil, err
+	}
+	start, end := f.window(startAt, len(f.boards))
+	return &jira.BoardsList{
+		MaxResults: f.pageSize,
+		StartAt:    start,
+		Total:      len(f.boards),
+		IsLast:     end == len(f.boards),
+		Values:     slices.Clone(f.boards[start:end]),
+	}, nil
+}
+
+func (f *FakeBackend) GetSprintsForBoard(boardID int, startAt int) (*jira.SprintsList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(fmt.Sprintf("fetching sprints for board %d", boardID)); err != nil {
+		return nil, err
+	}
+	sprints := make([]jira.Sprint, 0)
+	for _, sprint := range f.sprints {
+		if sprint.OriginBoardID == boardID {
+			sprints = append(sprints, sprint)
+		}
+	}
+	start, end := f.window(startAt, len(sprints))
+	return &jira.SprintsList{
+		MaxResults: f.pageSize,
+		StartAt:    start,
+		Total:      len(sprints),
+		IsLast:     end == len(sprints),
+		Values:     sprints[start:end],
+	}, nil
+}
+
+func (f *FakeBackend) GetIssuesForBoard(board jira.Board, startAt int) (*IssuesPage, error) {
+	op := fmt.Sprintf("fetching issues for board %s", board.Name)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	issues, err := f.boardIssues(op, board.ID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := f.window(startAt, len(issues))
+	return &IssuesPage{Issues: issues[start:end], StartAt: start, Total: len(issues)}, nil
+}
+
+// like jira, the backlog is whatever isn't done and isn't in an active or
+// closed sprint
+func (f *FakeBackend) GetBacklogForBoard(board jira.Board, startAt int) (*IssuesPage, error) {
+	op := fmt.Sprintf("fetching backlog for board %s", board.Name)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	issues, err := f.boardIssues(op, board.ID)
+	if err != nil {
+		return nil, err
+	}
+	issues = slices.DeleteFunc(issues, func(issue jira.Issue) bool {
+		fields := issue.Fields
+		if fields.Status != nil && fields.Status.StatusCategory.Key == "done" {
+			return true
+		}
+		return fields.Sprint != nil && fields.Sprint.State != "future"
+	})
+	start, end := f.window(startAt, len(issues))
+	return &IssuesPage{Issues: issues[start:end], StartAt: start, Total: len(issues)}, nil
+}
+
+// must hold f.mu. the issues matching a board's filter, and its sub-filter
+// if it's a kanban board.
+func (f *FakeBackend) boardIssues(op string, boardID int) ([]jira.Issue, error) {
+	configuration, err := f.boardConfiguration(op, boardID)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(f.filters, func(filter jira.Filter) bool { return filter.ID == configuration.Filter.ID })
+	if i < 0 {
+		return nil, fakeError(op, http.StatusNotFound, fmt.Errorf("The filter %s for this board does not exist.", configuration.Filter.ID))
+	}
+
+	queries := make([]jqlQuery, 0, 2)
+	for _, jql := range []string{f.filters[i].Jql, configuration.SubQuery.Query} {
+		if jql == "" {
+			continue
+		}
+		query, err := parseJQL(jql)
+		if err != nil {
+			return nil, fakeError(op, http.StatusBadRequest, err)
+		}
+		queries = append(queries, query)
+	}
+
+	issues := make([]jira.Issue, 0)
+	for _, issue := range f.issues {
+		if !slices.ContainsFunc(queries, func(q jqlQuery) bool { return !q.matches(issue, &f.user) }) {
+			copied, err := cloneIssue(issue)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, copied)
+		}
+	}
+	return issues, nil
+}
+
+func (f *FakeBackend) GetBoardConfiguration(boardID int) (*jira.BoardConfiguration, error) {
+	op := fmt.Sprintf("fetching configuration for board %d", boardID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	configuration, err := f.boardConfiguration(op, boardID)
+	if err != nil {
+		return nil, err
+	}
+	return &configuration, nil
+}
+
+// must hold f.mu
+func (f *FakeBackend) boardConfiguration(op string, boardID int) (jira.BoardConfiguration, error) {
+	i := slices.IndexFunc(f.configurations, func(c jira.BoardConfiguration) bool { return c.ID == boardID })
+	if i < 0 {
+		return jira.BoardConfiguration{}, fakeError(op, http.StatusNotFound, errors.New("The requested board cannot be viewed because it either does not exist or you do not have permission to view it."))
+	}
+	return f.configurations[i], nil
+}
+
+func (f *FakeBackend) GetIssuesForSprint(sprint jira.Sprint, startAt int) (*IssuesPage, error) {
+	return f.SearchIssues(fmt.Sprintf("sprint = %d", sprint.ID), startAt)
+}
+
+func (f *FakeBackend) SearchIssues(jql string, startAt int) (*IssuesPage, error) {
+	query, err := parseJQL(jql)
+	if err != nil {
+		return nil, fakeError("searching issues", http.StatusBadRequest, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure("searching issues"); err != nil {
+		return nil, err
+	}
+	issues := make([]jira.Issue, 0)
+	for _, issue := range f.issues {
+		if query.matches(issue, &f.user) {
+			copied, err := cloneIssue(issue)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, copied)
+		}
+	}
+	start, end := f.window(startAt, len(issues))
+	return &IssuesPage{Issues: issues[start:end], StartAt: start, Total: len(issues)}, nil
+}
+
+func (f *FakeBackend) GetIssue(issueID string) (*jira.Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(fmt.Sprintf("fetching issue %s", issueID)); err != nil {
+		return nil, err
+	}
+	for _, issue := range f.issues {
+		if issue.ID == issueID || issue.Key == issueID {
+			// like expand=names
+			issue.Names = make(map[string]string)
+			for _, field := range f.fields {
+				issue.Names[field.ID] = field.Name
+			}
+			copied, err := cloneIssue(issue)
+			if err != nil {
+				return nil, err
+			}
+			return &copied, nil
+		}
+	}
+	return nil, fakeError(fmt.Sprintf("fetching issue %s", issueID), http.StatusNotFound, errors.New("Issue Does Not Exist"))
+}
+
+// must hold f.mu
+func (f *FakeBackend) issueIndex(op string, issueID string) (int, error) {
+	i := slices.IndexFunc(f.issues, func(issue jira.Issue) bool { return issue.ID == issueID || issue.Key == issueID })
+	if i < 0 {
+		return i, fakeError(op, http.StatusNotFound, errors.New("Issue Does Not Exist"))
+	}
+	return i, nil
+}
+
+// every transition that goes somewhere other than where the issue is
+func (f *FakeBackend) GetTransitions(issueID string) ([]Transition, error) {
+	op := fmt.Sprintf("fetching transitions for %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return nil, err
+	}
+	return f.availableTransitions(f.issues[i]), nil
+}
+
+// must hold f.mu
+func (f *FakeBackend) availableTransitions(issue jira.Issue) []Transition {
+	return slices.DeleteFunc(slices.Clone(f.transitions), func(t Transition) bool {
+		return issue.Fields.Status != nil && t.To.ID == issue.Fields.Status.ID
+	})
+}
+
+func (f *FakeBackend) DoTransition(issueID string, transitionID string, fields map[string]string) error {
+	op := fmt.Sprintf("transitioning %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return err
+	}
+	available := f.availableTransitions(f.issues[i])
+	t := slices.IndexFunc(available, func(t Transition) bool { return t.ID == transitionID })
+	if t < 0 {
+		return fakeError(op, http.StatusBadRequest, fmt.Errorf("Transition id '%s' is not valid for this issue.", transitionID))
+	}
+	transition := available[t]
+
+	// like jira, complain about the first required field that's missing
+	var resolution *jira.Resolution
+	for id, field := range transition.Fields {
+		value, ok := fields[id]
+		if !ok {
+			if field.Required {
+				return fakeError(op, http.StatusBadRequest, fmt.Errorf("Field '%s' is required.", field.Name))
+			}
+			continue
+		}
+		allowed := slices.IndexFunc(field.AllowedValues, func(v AllowedValue) bool { return v.ID == value })
+		if allowed < 0 {
+			return fakeError(op, http.StatusBadRequest, fmt.Errorf("Could not find valid 'id' or 'name' in %s object.", field.Name))
+		}
+		if id == "resolution" {
+			resolution = &jira.Resolution{ID: value, Name: field.AllowedValues[allowed].Name}
+		}
+	}
+
+	status := transition.To
+	f.issues[i].Fields.Status = &status
+	f.issues[i].Fields.Resolution = resolution
+	return nil
+}
+
+func (f *FakeBackend) GetCreateMeta() ([]CreateMetaProject, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure("fetching create metadata"); err != nil {
+		return nil, err
+	}
+	projects, err := cloneFixture(f.createMeta)
+	for i := range projects {
+		projects[i].IssueTypes = nil
+	}
+	return projects, err
+}
+
+func (f *FakeBackend) GetCreateIssueTypes(projectKey string) ([]CreateMetaIssueType, error) {
+	op := fmt.Sprintf("fetching issue types for %s", projectKey)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(f.createMeta, func(p CreateMetaProject) bool { return p.Key == projectKey })
+	if i < 0 {
+		return nil, fakeError(op, http.StatusNotFound, errors.New("No project could be found with key '"+projectKey+"'."))
+	}
+	issueTypes, err := cloneFixture(f.createMeta[i].IssueTypes)
+	for i := range issueTypes {
+		issueTypes[i].Fields = nil
+	}
+	return issueTypes, err
+}
+
+func (f *FakeBackend) GetCreateFields(projectKey string, issueTypeID string) (map[string]FieldMeta, error) {
+	op := fmt.Sprintf("fetching fields for %s", projectKey)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	for _, project := range f.createMeta {
+		if project.Key != projectKey {
+			continue
+		}
+		for _, issueType := range project.IssueTypes {
+			if issueType.ID == issueTypeID {
+				return cloneFixture(issueType.Fields)
+			}
+		}
+	}
+	return nil, fakeError(op, http.StatusNotFound, errors.New("Issue Type with id '"+issueTypeID+"' does not exist."))
+}
+
+// like jira, every field is checked against the issue type's screen before
+// anything is made, and each one that's wrong says why
+func (f *FakeBackend) CreateIssue(fields map[string]any) (*jira.Issue, error) {
+	op := "creating issue"
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+
+	fields = maps.Clone(fields)
+	problems := make(map[string]string)
+	project, issueType := f.createScreen(fields, problems)
+	if issueType != nil {
+		for id, meta := range issueType.Fields {
+			if value, ok := fields[id]; meta.Required && (!ok || emptyFieldValue(value)) {
+				problems[id] = fmt.Sprintf("%s is required.", meta.Name)
+			}
+		}
+		for id, value := range fields {
+			if id == "project" || id == "issuetype" || problems[id] != "" {
+				continue
+			}
+			meta, ok := issueType.Fields[id]
+			if !ok {
+				problems[id] = fmt.Sprintf("Field '%s' cannot be set. It is not on the appropriate screen, or unknown.", id)
+				continue
+			}
+			resolved, problem := resolveFieldValue(meta, value)
+			if problem != "" {
+				problems[id] = problem
+				continue
+			}
+			fields[id] = resolved
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fakeFieldErrors(op, problems)
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fakeError(op, http.StatusBadRequest, err)
+	}
+	issue := jira.Issue{Fields: &jira.IssueFields{}}
+	if err := json.Unmarshal(raw, issue.Fields); err != nil {
+		return nil, fakeError(op, http.StatusBadRequest, err)
+	}
+
+	lastID, lastNumber := 0, 0
+	for _, existing := range f.issues {
+		id, _ := strconv.Atoi(existing.ID)
+		lastID = max(lastID, id)
+		if number, ok := strings.CutPrefix(existing.Key, project.Key+"-"); ok {
+			n, _ := strconv.Atoi(number)
+			lastNumber = max(lastNumber, n)
+		}
+	}
+	issue.ID = strconv.Itoa(lastID + 1)
+	issue.Key = fmt.Sprintf("%s-%d", project.Key, lastNumber+1)
+	issue.Self = "fake://issue/" + issue.ID
+
+	now := jira.Time(time.Now())
+	reporter := f.user
+	issue.Fields.Project = jira.Project{ID: project.ID, Key: project.Key, Name: project.Name}
+	issue.Fields.Type = jira.IssueType{ID: issueType.ID, Name: issueType.Name, Subtask: issueType.Subtask}
+	issue.Fields.Reporter = &reporter
+	issue.Fields.Created, issue.Fields.Updated = now, now
+	issue.Fields.Comments = &jira.Comments{Comments: make([]*jira.Comment, 0)}
+	// new issues start at the top of the workflow
+	if len(f.transitions) > 0 {
+		status := f.transitions[0].To
+		issue.Fields.Status = &status
+	}
+	f.issues = append(f.issues, issue)
+
+	// jira only says where to find it
+	return &jira.Issue{ID: issue.ID, Key: issue.Key, Self: issue.Self}, nil
+}
+
+// every project's editmeta is the same for all of its issues
+func (f *FakeBackend) GetEditMeta(issueID string) (map[string]FieldMeta, error) {
+	op := fmt.Sprintf("fetching editable fields of %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return nil, err
+	}
+	return cloneFixture(f.editMeta[f.issues[i].Fields.Project.Key])
+}
+
+// checked against editmeta the same way CreateIssue checks createmeta
+func (f *FakeBackend) UpdateIssue(issueID string, fields map[string]any) error {
+	op := fmt.Sprintf("updating %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return err
+	}
+
+	screen := f.editMeta[f.issues[i].Fields.Project.Key]
+	problems := make(map[string]string)
+	resolved := make(map[string]any)
+	for id, value := range fields {
+		meta, ok := screen[id]
+		switch {
+		case !ok:
+			problems[id] = fmt.Sprintf("Field '%s' cannot be set. It is not on the appropriate screen, or unknown.", id)
+		case emptyFieldValue(value) && meta.Required:
+			problems[id] = fmt.Sprintf("%s is required.", meta.Name)
+		case value == nil:
+			resolved[id] = nil
+		case meta.Schema.Type == "user":
+			user, problem := f.findUser(value)
+			if problem != "" {
+				problems[id] = problem
+			}
+			resolved[id] = user
+		default:
+			value, problem := resolveFieldValue(meta, value)
+			if problem != "" {
+				problems[id] = problem
+			}
+			resolved[id] = value
+		}
+	}
+	if len(problems) > 0 {
+		return fakeFieldErrors(op, problems)
+	}
+
+	// lay the changes over the issue as jira would return it, and read it
+	// back in
+	raw, err := json.Marshal(f.issues[i].Fields)
+	if err != nil {
+		return fakeError(op, http.StatusInternalServerError, err)
+	}
+	current := make(map[string]any)
+	if err := json.Unmarshal(raw, &current); err != nil {
+		return fakeError(op, http.StatusInternalServerError, err)
+	}
+	for id, value := range resolved {
+		if value == nil {
+			delete(current, id)
+			continue
+		}
+		current[id] = value
+	}
+	current["updated"] = jira.Time(time.Now())
+	if raw, err = json.Marshal(current); err != nil {
+		return fakeError(op, http.StatusBadRequest, err)
+	}
+	updated := &jira.IssueFields{}
+	if err := json.Unmarshal(raw, updated); err != nil {
+		return fakeError(op, http.StatusBadRequest, err)
+	}
+	f.issues[i].Fields = updated
+	return nil
+}
+
+// must hold f.mu. who a value like {"accountId": "..."} is.
+func (f *FakeBackend) findUser(value any) (*jira.User, string) {
+	ref := struct {
+		AccountID string `json:"accountId"`
+		Name      string `json:"name"`
+	}{}
+	raw, _ := json.Marshal(value)
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		return nil, "expected Object"
+	}
+	for _, user := range f.users {
+		if (ref.AccountID != "" && user.AccountID == ref.AccountID) || (ref.Name != "" && user.Name == ref.Name) {
+			return &user, ""
+		}
+	}
+	return nil, fmt.Sprintf("User '%s' does not exist.", cmp.Or(ref.AccountID, ref.Name))
+}
+
+// like jira's user search, anyone active whose name or email has query in
+// it. everyone can be assigned anything here, so scope doesn't narrow it.
+func (f *FakeBackend) FindUsers(query string, scope UserScope) ([]jira.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure("finding users"); err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	users := make([]jira.User, 0)
+	if query == "" {
+		return users, nil
+	}
+	for _, user := range f.users {
+		if user.Active && (strings.Contains(strings.ToLower(user.DisplayName), query) || strings.Contains(strings.ToLower(user.EmailAddress), query)) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// every label on any issue that starts with prefix
+func (f *FakeBackend) SuggestLabels(prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure("suggesting labels"); err != nil {
+		return nil, err
+	}
+	prefix = strings.ToLower(prefix)
+	labels := make([]string, 0)
+	for _, issue := range f.issues {
+		for _, label := range issue.Fields.Labels {
+			if strings.HasPrefix(strings.ToLower(label), prefix) && !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	slices.Sort(labels)
+	return labels, nil
+}
+
+// must hold f.mu. the project and issue type fields points at, with what's
+// wrong with them in problems.
+func (f *FakeBackend) createScreen(fields map[string]any, problems map[string]string) (*CreateMetaProject, *CreateMetaIssueType) {
+	ref := func(id string) fieldRef {
+		refs, err := decodeFieldRefs(fields[id], false)
+		if err != nil || len(refs) == 0 {
+			return fieldRef{}
+		}
+		return refs[0]
+	}
+
+	projectRef := ref("project")
+	i := slices.IndexFunc(f.createMeta, func(p CreateMetaProject) bool {
+		return (projectRef.ID != "" && p.ID == projectRef.ID) || (projectRef.Key != "" && p.Key == projectRef.Key)
+	})
+	if i < 0 {
+		problems["project"] = "Specify a valid project ID or key"
+		return nil, nil
+	}
+	project := &f.createMeta[i]
+
+	typeRef := ref("issuetype")
+	i = slices.IndexFunc(project.IssueTypes, func(t CreateMetaIssueType) bool {
+		return (typeRef.ID != "" && t.ID == typeRef.ID) || (typeRef.Name != "" && t.Name == typeRef.Name)
+	})
+	if i < 0 {
+		problems["issuetype"] = "Specify an issue type"
+		return project, nil
+	}
+	return project, &project.IssueTypes[i]
+}
+
+// nothing, or nothing but whitespace
+func emptyFieldValue(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	}
+	v := reflect.ValueOf(value)
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0
+}
+
+// check value is the right shape for the field, filling in the whole of any
+// allowed value it picks, as jira would when the issue is fetched
+func resolveFieldValue(meta FieldMeta, value any) (any, string) {
+	switch {
+	case len(meta.AllowedValues) > 0:
+		array := meta.Schema.Type == "array"
+		refs, err := decodeFieldRefs(value, array)
+		if err != nil {
+			return nil, fmt.Sprintf("Could not find valid 'id' or 'name' in %s object.", meta.Name)
+		}
+		values := make([]AllowedValue, 0, len(refs))
+		for _, ref := range refs {
+			i := slices.IndexFunc(meta.AllowedValues, func(v AllowedValue) bool {
+				return (ref.ID != "" && v.ID == ref.ID) || (ref.Name != "" && v.Name == ref.Name) || (ref.Value != "" && v.Value == ref.Value)
+			})
+			if i < 0 {
+				return nil, fmt.Sprintf("Could not find valid 'id' or 'name' in %s object.", meta.Name)
+			}
+			values = append(values, meta.AllowedValues[i])
+		}
+		if !array {
+			return values[0], ""
+		}
+		return values, ""
+	case meta.Schema.Type == "number":
+		if _, ok := value.(float64); !ok {
+			return nil, fmt.Sprintf("Operation value must be a number for %s.", meta.Name)
+		}
+	case meta.Schema.Type == "date":
+		s, _ := value.(string)
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return nil, fmt.Sprintf("Error parsing date string: %v", value)
+		}
+	}
+	return value, ""
+}
+
+// comment timestamps look like 2024-01-15T09:30:00.000+0000
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+func (f *FakeBackend) AddComment(issueID string, body string) (*jira.Comment, error) {
+	op := fmt.Sprintf("commenting on %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := f.issues[i].Fields
+	if fields.Comments == nil {
+		fields.Comments = &jira.Comments{}
+	}
+	f.lastCommentID++
+	now := time.Now().Format(jiraTimeLayout)
+	comment := &jira.Comment{
+		ID:           strconv.Itoa(f.lastCommentID),
+		Author:       f.user,
+		UpdateAuthor: f.user,
+		Body:         body,
+		Created:      now,
+		Updated:      now,
+	}
+	fields.Comments.Comments = append(fields.Comments.Comments, comment)
+	copied := *comment
+	return &copied, nil
+}
+
+func (f *FakeBackend) UpdateComment(issueID string, commentID string, body string) (*jira.Comment, error) {
+	op := fmt.Sprintf("editing comment on %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	comment, err := f.ownComment(op, issueID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	comment.Body = body
+	comment.UpdateAuthor = f.user
+	comment.Updated = time.Now().Format(jiraTimeLayout)
+	copied := *comment
+	return &copied, nil
+}
+
+func (f *FakeBackend) DeleteComment(issueID string, commentID string) error {
+	op := fmt.Sprintf("deleting comment on %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return err
+	}
+	if _, err := f.ownComment(op, issueID, commentID); err != nil {
+		return err
+	}
+	i, _ := f.issueIndex(op, issueID)
+	comments := f.issues[i].Fields.Comments
+	comments.Comments = slices.DeleteFunc(comments.Comments, func(c *jira.Comment) bool { return c.ID == commentID })
+	return nil
+}
+
+// must hold f.mu. only the author gets to change a comment, like a jira
+// without admins.
+func (f *FakeBackend) ownComment(op string, issueID string, commentID string) (*jira.Comment, error) {
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if comments := f.issues[i].Fields.Comments; comments != nil {
+		for _, comment := range comments.Comments {
+			if comment.ID != commentID {
+				continue
+			}
+			if comment.Author.AccountID != f.user.AccountID {
+				return nil, fakeError(op, http.StatusForbidden, errors.New("You do not have the permission to edit this comment."))
+			}
+			return comment, nil
+		}
+	}
+	return nil, fakeError(op, http.StatusNotFound, fmt.Errorf("Can not find a comment for the id: %s.", commentID))
+}
+
+func (f *FakeBackend) GetWorklogs(issueID string) ([]jira.WorklogRecord, error) {
+	op := fmt.Sprintf("fetching worklogs for %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return nil, err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if worklog := f.issues[i].Fields.Worklog; worklog != nil {
+		return cloneFixture(worklog.Worklogs)
+	}
+	return make([]jira.WorklogRecord, 0), nil
+}
+
+// log the work as f.user, and move the issue's time tracking along like
+// jira would
+func (f *FakeBackend) AddWorklog(issueID string, worklog jira.WorklogRecord, estimate RemainingEstimate) error {
+	op := fmt.Sprintf("logging work on %s", issueID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.takeFailure(op); err != nil {
+		return err
+	}
+	i, err := f.issueIndex(op, issueID)
+	if err != nil {
+		return err
+	}
+
+	problems := make(map[string]string)
+	spent, err := parseWorklogDuration(worklog.TimeSpent)
+	if err != nil || spent <= 0 {
+		problems["timeLogged"] = "Invalid time duration entered."
+	}
+	if worklog.Started == nil {
+		problems["startDate"] = "You must specify a valid start date."
+	}
+	remaining := 0
+	switch estimate.Adjust {
+	case estimateNew:
+		if remaining, err = parseWorklogDuration(estimate.New); err != nil {
+			problems["newEstimate"] = "You must supply a valid new estimate."
+		}
+	case estimateAuto, estimateLeave, "":
+	default:
+		problems["adjustEstimate"] = fmt.Sprintf("Invalid value for adjustEstimate: %s", estimate.Adjust)
+	}
+	if len(problems) > 0 {
+		return fakeFieldErrors(op, problems)
+	}
+
+	fields := f.issues[i].Fields
+	now := jira.Time(time.Now())
+	f.lastWorklogID++
+	author := f.user
+	worklog.ID = strconv.Itoa(f.lastWorklogID)
+	worklog.IssueID = f.issues[i].ID
+	worklog.Author, worklog.UpdateAuthor = &author, &author
+	worklog.Created, worklog.Updated = &now, &now
+	worklog.TimeSpent, worklog.TimeSpentSeconds = formatWorklogDuration(spent), spent
+	if fields.Worklog == nil {
+		fields.Worklog = &jira.Worklog{}
+	}
+	fields.Worklog.Worklogs = append(fields.Worklog.Worklogs, worklog)
+	fields.Worklog.Total = len(fields.Worklog.Worklogs)
+
+	tracking := fields.TimeTracking
+	if tracking == nil {
+		tracking = &jira.TimeTracking{}
+		fields.TimeTracking = tracking
+	}
+	tracking.TimeSpentSeconds += spent
+	tracking.TimeSpent = formatWorklogDuration(tracking.TimeSpentSeconds)
+	switch {
+	case estimate.Adjust == estimateNew:
+		tracking.RemainingEstimateSeconds = remaining
+		tracking.RemainingEstimate = formatWorklogDuration(remaining)
+	// without an estimate there's nothing to take the time off
+	case estimate.Adjust != estimateLeave && tracking.RemainingEstimate != "":
+		tracking.RemainingEstimateSeconds = max(tracking.RemainingEstimateSeconds-spent, 0)
+		tracking.RemainingEstimate = formatWorklogDuration(tracking.RemainingEstimateSeconds)
+	}
+	fields.Updated = now
+	return nil
+}
+
+// a 400 saying what's wrong with each field, as jira words it
+func fakeFieldErrors(op string, problems map[string]string) error {
+	return fakeError(op, http.StatusBadRequest, &jira.Error{
+		HTTPError: fmt.Errorf("request failed. Please analyze the request body for more details. Status code: %d", http.StatusBadRequest),
+		Errors:    problems,
+	})
+}
+
+// the same shape of error JiraData would hand back
+func fakeError(op string, statusCode int, err error) error {
+	return &RequestError{Op: op, StatusCode: statusCode, Err: err}
+}
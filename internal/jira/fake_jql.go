@@ -0,0 +1,259 @@
+package jira
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// just enough JQL for the fake backend: `field op value` clauses joined with
+// AND/OR, where op is one of = != ~ in, "not in", is or "is not". ORDER BY is accepted
+// and ignored.
+type jqlClause struct {
+	field  string
+	op     string
+	values []string
+}
+
+// clauses are ANDed together, and the groups are ORed
+type jqlQuery [][]jqlClause
+
+func parseJQL(jql string) (jqlQuery, error) {
+	tokens, err := tokenizeJQL(jql)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop the ORDER BY, we don't sort
+	for i := 0; i+1 < len(tokens); i++ {
+		if strings.EqualFold(tokens[i], "order") && strings.EqualFold(tokens[i+1], "by") {
+			tokens = tokens[:i]
+			break
+		}
+	}
+
+	query := jqlQuery{{}}
+	for i := 0; i < len(tokens); {
+		// field
+		field := strings.ToLower(tokens[i])
+		if !slices.Contains(jqlFields, field) {
+			return nil, fmt.Errorf("Field '%s' does not exist or you do not have permission to view it.", tokens[i])
+		}
+		i++
+
+		// operator
+		if i >= len(tokens) {
+			return nil, fmt.Errorf("Error in the JQL Query: Expecting operator but got the end of the query.")
+		}
+		op := strings.ToLower(tokens[i])
+		if op == "not" && i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "in") {
+			op = "not in"
+			i++
+		}
+		if op == "is" && i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "not") {
+			op = "is not"
+			i++
+		}
+		if !slices.Contains([]string{"=", "!=", "~", "in", "not in", "is", "is not"}, op) {
+			return nil, fmt.Errorf("Error in the JQL Query: Expecting operator but got '%s'.", tokens[i])
+		}
+		i++
+
+		// value(s)
+		values := make([]string, 0)
+		if op == "in" || op == "not in" {
+			if i >= len(tokens) || tokens[i] != "(" {
+				return nil, fmt.Errorf("Error in the JQL Query: Expecting '(' after '%s'.", op)
+			}
+			i++
+			for i < len(tokens) && tokens[i] != ")" {
+				if tokens[i] != "," {
+					values = append(values, tokens[i])
+				}
+				i++
+			}
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("Error in the JQL Query: Expecting ')' before the end of the query.")
+			}
+			i++
+		} else {
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("Error in the JQL Query: Expecting either a value, list or function but got the end of the query.")
+			}
+			values = append(values, tokens[i])
+			i++
+		}
+		query[len(query)-1] = append(query[len(query)-1], jqlClause{field: field, op: op, values: values})
+
+		// conjunction
+		if i < len(tokens) {
+			switch strings.ToLower(tokens[i]) {
+			case "and":
+			case "or":
+				query = append(query, []jqlClause{})
+			default:
+				return nil, fmt.Errorf("Error in the JQL Query: Expecting either 'OR' or 'AND' but got '%s'.", tokens[i])
+			}
+			i++
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("Error in the JQL Query: Expecting a field name but got the end of the query.")
+			}
+		}
+	}
+	return query, nil
+}
+
+var jqlFields = []string{
+	"project", "key", "issuekey", "status", "statuscategory", "assignee",
+	"reporter", "sprint", "labels", "type", "issuetype", "priority", "summary", "text",
+}
+
+func tokenizeJQL(jql string) ([]string, error) {
+	tokens := make([]string, 0)
+	runes := []rune(jql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',' || r == '=' || r == '~':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, "!=")
+			i += 2
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("Error in the JQL Query: The quoted string '%s' has not been completed.", string(runes[i+1:]))
+			}
+			tokens = append(tokens, string(runes[i+1:end]))
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("(),=!~\"'", runes[end]) {
+				end++
+			}
+			// functions like currentUser() are a single token
+			if end+1 < len(runes) && runes[end] == '(' && runes[end+1] == ')' {
+				end += 2
+			}
+			if end == i {
+				return nil, fmt.Errorf("Error in the JQL Query: The character '%c' is a reserved JQL character.", r)
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("Error in the JQL Query: The query is empty.")
+	}
+	return tokens, nil
+}
+
+func (q jqlQuery) matches(issue jira.Issue, self *jira.User) bool {
+	for _, group := range q {
+		all := true
+		for _, clause := range group {
+			if !clause.matches(issue, self) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+func (c jqlClause) matches(issue jira.Issue, self *jira.User) bool {
+	actual := issueFieldValues(issue, c.field)
+
+	switch c.op {
+	case "~":
+		for _, a := range actual {
+			if strings.Contains(strings.ToLower(a), strings.ToLower(c.values[0])) {
+				return true
+			}
+		}
+		return false
+	case "is", "is not":
+		empty := len(actual) == 0
+		if !strings.EqualFold(c.values[0], "empty") && !strings.EqualFold(c.values[0], "null") {
+			empty = !empty
+		}
+		return empty == (c.op == "is")
+	}
+
+	found := false
+	for _, want := range c.values {
+		if strings.EqualFold(want, "currentUser()") && self != nil {
+			want = self.AccountID
+		}
+		for _, a := range actual {
+			if strings.EqualFold(a, want) {
+				found = true
+			}
+		}
+	}
+	if c.op == "!=" || c.op == "not in" {
+		return !found
+	}
+	return found
+}
+
+// the values a field could be compared against, e.g. a sprint matches on
+// both its name and its id
+func issueFieldValues(issue jira.Issue, field string) []string {
+	f := issue.Fields
+	values := make([]string, 0)
+	user := func(u *jira.User) {
+		if u != nil {
+			values = append(values, u.AccountID, u.DisplayName, u.EmailAddress, u.Name)
+		}
+	}
+
+	switch field {
+	case "project":
+		values = append(values, f.Project.Key, f.Project.Name, f.Project.ID)
+	case "key", "issuekey":
+		values = append(values, issue.Key, issue.ID)
+	case "status":
+		if f.Status != nil {
+			values = append(values, f.Status.Name, f.Status.ID)
+		}
+	case "statuscategory":
+		if f.Status != nil {
+			values = append(values, f.Status.StatusCategory.Name, f.Status.StatusCategory.Key)
+		}
+	case "assignee":
+		user(f.Assignee)
+	case "reporter":
+		user(f.Reporter)
+	case "sprint":
+		if f.Sprint != nil {
+			values = append(values, f.Sprint.Name, strconv.Itoa(f.Sprint.ID))
+		}
+	case "labels":
+		values = append(values, f.Labels...)
+	case "type", "issuetype":
+		values = append(values, f.Type.Name, f.Type.ID)
+	case "priority":
+		if f.Priority != nil {
+			values = append(values, f.Priority.Name, f.Priority.ID)
+		}
+	case "summary":
+		values = append(values, f.Summary)
+	case "text":
+		values = append(values, f.Summary, f.Description)
+	}
+	return slices.DeleteFunc(values, func(s string) bool { return s == "" })
+}
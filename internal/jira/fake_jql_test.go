@@ -0,0 +1,136 @@
+package jira
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestParseJQLErrors(t *testing.T) {
+	tests := []struct {
+		jql  string
+		want string // part of the error
+	}{
+		{"", "The query is empty"},
+		{"   ", "The query is empty"},
+		{"bogus = 1", "Field 'bogus' does not exist"},
+		{"project", "Expecting operator but got the end"},
+		{"project > TUI", "Expecting operator but got '>'"},
+		{"project ! TUI", "reserved JQL character"},
+		{"project like TUI", "Expecting operator but got 'like'"},
+		{"project =", "Expecting either a value"},
+		{"project in TUI", "Expecting '(' after 'in'"},
+		{"project in (TUI, OPS", "Expecting ')'"},
+		{"project = TUI and", "Expecting a field name"},
+		{"project = TUI xor key = TUI-1", "Expecting either 'OR' or 'AND'"},
+		{`summary ~ "crash`, "has not been completed"},
+		{"assignee is not", "Expecting either a value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jql, func(t *testing.T) {
+			_, err := parseJQL(tt.jql)
+			if err == nil {
+				t.Fatalf("parseJQL(%q) = nil error, want one containing %q", tt.jql, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseJQL(%q) = %q, want it to contain %q", tt.jql, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJQLOperators(t *testing.T) {
+	tests := []struct {
+		jql    string
+		op     string
+		values []string
+	}{
+		{"project = TUI", "=", []string{"TUI"}},
+		{"project != TUI", "!=", []string{"TUI"}},
+		{"summary ~ 'token expires'", "~", []string{"token expires"}},
+		{"project in (TUI, OPS)", "in", []string{"TUI", "OPS"}},
+		{"project NOT IN (TUI)", "not in", []string{"TUI"}},
+		{"assignee is EMPTY", "is", []string{"EMPTY"}},
+		{"assignee IS NOT empty", "is not", []string{"empty"}},
+		{"assignee = currentUser() ORDER BY updated DESC", "=", []string{"currentUser()"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jql, func(t *testing.T) {
+			query, err := parseJQL(tt.jql)
+			if err != nil {
+				t.Fatalf("parseJQL(%q): %v", tt.jql, err)
+			}
+			if len(query) != 1 || len(query[0]) != 1 {
+				t.Fatalf("parseJQL(%q) = %v, want one clause", tt.jql, query)
+			}
+			clause := query[0][0]
+			if clause.op != tt.op || strings.Join(clause.values, "|") != strings.Join(tt.values, "|") {
+				t.Errorf("parseJQL(%q) = %s %v, want %s %v", tt.jql, clause.op, clause.values, tt.op, tt.values)
+			}
+		})
+	}
+}
+
+func TestJQLMatches(t *testing.T) {
+	self := &jira.User{AccountID: "me", DisplayName: "Offline User"}
+	mine := jira.Issue{Key: "TUI-1", ID: "10001", Fields: &jira.IssueFields{
+		Project:  jira.Project{Key: "TUI", Name: "Terminal UI"},
+		Status:   &jira.Status{Name: "In Progress", StatusCategory: jira.StatusCategory{Key: "indeterminate", Name: "In Progress"}},
+		Assignee: self,
+		Labels:   []string{"bug", "auth"},
+		Type:     jira.IssueType{Name: "Bug"},
+		Sprint:   &jira.Sprint{ID: 2, Name: "TUI Sprint 2"},
+		Priority: &jira.Priority{Name: "High"},
+		Summary:  "Crash when the token expires",
+	}}
+	unassigned := jira.Issue{Key: "OPS-7", ID: "10007", Fields: &jira.IssueFields{
+		Project: jira.Project{Key: "OPS"},
+		Status:  &jira.Status{Name: "To Do", StatusCategory: jira.StatusCategory{Key: "new", Name: "To Do"}},
+		Type:    jira.IssueType{Name: "Task"},
+		Summary: "Rotate the certificates",
+	}}
+
+	tests := []struct {
+		jql  string
+		want []string // keys of the issues that match
+	}{
+		{"project = TUI", []string{"TUI-1"}},
+		{"project = tui", []string{"TUI-1"}},
+		{"project != TUI", []string{"OPS-7"}},
+		{"key = 10007", []string{"OPS-7"}},
+		{"project in (TUI, OPS)", []string{"TUI-1", "OPS-7"}},
+		{"project not in (TUI)", []string{"OPS-7"}},
+		{"summary ~ TOKEN", []string{"TUI-1"}},
+		{"text ~ certificates", []string{"OPS-7"}},
+		{"assignee is EMPTY", []string{"OPS-7"}},
+		{"assignee is null", []string{"OPS-7"}},
+		{"assignee is not EMPTY", []string{"TUI-1"}},
+		{"assignee = currentUser()", []string{"TUI-1"}},
+		{`assignee = "Offline User"`, []string{"TUI-1"}},
+		{"sprint = 2", []string{"TUI-1"}},
+		{"sprint is EMPTY", []string{"OPS-7"}},
+		{"labels in (auth)", []string{"TUI-1"}},
+		{"statusCategory != Done", []string{"TUI-1", "OPS-7"}},
+		{"statusCategory = new OR priority = High", []string{"TUI-1", "OPS-7"}},
+		{"project = TUI AND type = Task", nil},
+		{"project = OPS AND type = Task OR labels = bug", []string{"TUI-1", "OPS-7"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jql, func(t *testing.T) {
+			query, err := parseJQL(tt.jql)
+			if err != nil {
+				t.Fatalf("parseJQL(%q): %v", tt.jql, err)
+			}
+			var got []string
+			for _, issue := range []jira.Issue{mine, unassigned} {
+				if query.matches(issue, self) {
+					got = append(got, issue.Key)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%q matched %v, want %v", tt.jql, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,492 @@
+package jira
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+	"github.com/guppy0130/go-jira-tui/pkg/m2j"
+)
+
+// how a field gets filled in
+type formFieldKind int
+
+const (
+	textField   formFieldKind = iota // typed in: text, numbers, dates and labels
+	selectField                      // one of its allowed values
+	multiField                       // any of its allowed values
+	userField                        // someone found by searching
+)
+
+// how long typing has to pause before suggestions are looked up
+const suggestDelay = 250 * time.Millisecond
+
+// how many people a search shows at once
+const maxUserResults = 5
+
+// one field of a form, going by what jira says about it
+type formField struct {
+	id      string
+	meta    FieldMeta
+	kind    formFieldKind
+	input   textinput.Model // text fields, and the search for user fields
+	choice  int             // select fields: the allowed value picked, -1 for none
+	picked  map[string]bool // multi fields: ids of the allowed values picked
+	cursor  int             // the allowed value or suggestion under the cursor
+	focused bool
+	err     string // what's wrong with what's in it
+
+	users       []jira.User // user fields: who matches the search
+	chosen      *jira.User  // user fields: who was picked, nil for nobody
+	suggestions []string    // labels: what the word being typed could be
+}
+
+// what a value like {"id": "3"}, or a list of them, points at
+type fieldRef struct {
+	ID    string `json:"id"`
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func decodeFieldRefs(value any, array bool) ([]fieldRef, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if !array {
+		ref := fieldRef{}
+		return []fieldRef{ref}, json.Unmarshal(raw, &ref)
+	}
+	refs := make([]fieldRef, 0)
+	return refs, json.Unmarshal(raw, &refs)
+}
+
+// a field for meta, if it's one we know how to fill in
+func newFormField(id string, meta FieldMeta) (formField, bool) {
+	switch {
+	case len(meta.AllowedValues) > 0 && meta.Schema.Type == "array":
+		return formField{id: id, meta: meta, kind: multiField, picked: make(map[string]bool)}, true
+	case len(meta.AllowedValues) > 0:
+		return newSelectField(id, meta), true
+	case meta.Schema.Type == "user":
+		return formField{id: id, meta: meta, kind: userField, input: newFieldInput("type to search")}, true
+	case slices.Contains([]string{"string", "number", "date"}, meta.Schema.Type),
+		meta.Schema.Type == "array" && meta.Schema.Items == "string":
+		return formField{id: id, meta: meta, kind: textField, input: newFieldInput(fieldPlaceholder(meta))}, true
+	}
+	return formField{}, false
+}
+
+func newSelectField(id string, meta FieldMeta) formField {
+	return formField{id: id, meta: meta, kind: selectField, choice: -1}
+}
+
+// the cursor doesn't blink, so fields don't need to be redrawn for it
+func newFieldInput(placeholder string) textinput.Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = placeholder
+	input.Cursor.SetMode(cursor.CursorStatic)
+	return input
+}
+
+// a hint at what goes in a text field
+func fieldPlaceholder(meta FieldMeta) string {
+	switch {
+	case meta.Schema.Type == "date":
+		return "YYYY-MM-DD"
+	case meta.Schema.Type == "array":
+		return "separated by spaces"
+	case meta.Schema.System == "description":
+		return "markdown"
+	}
+	return ""
+}
+
+// start from what the field holds now, as it comes out of an issue's
+// fields, e.g. {"id": "3", "name": "Medium"} for a priority
+func (f *formField) fill(current any) {
+	if current == nil {
+		return
+	}
+	switch f.kind {
+	case selectField:
+		if refs, err := decodeFieldRefs(current, false); err == nil {
+			f.choice = slices.IndexFunc(f.meta.AllowedValues, func(v AllowedValue) bool { return v.ID == refs[0].ID })
+		}
+	case multiField:
+		if refs, err := decodeFieldRefs(current, true); err == nil {
+			for _, ref := range refs {
+				f.picked[ref.ID] = true
+			}
+		}
+	case userField:
+		raw, _ := json.Marshal(current)
+		user := &jira.User{}
+		if json.Unmarshal(raw, user) == nil {
+			f.chosen = user
+			f.input.SetValue(user.DisplayName)
+		}
+	default:
+		switch current := current.(type) {
+		case string:
+			f.input.SetValue(current)
+		case float64:
+			f.input.SetValue(strconv.FormatFloat(current, 'f', -1, 64))
+		case []any:
+			words := make([]string, 0, len(current))
+			for _, word := range current {
+				words = append(words, fmt.Sprint(word))
+			}
+			f.input.SetValue(strings.Join(words, " "))
+		}
+	}
+}
+
+// what jira should be sent for the field, or nil if it's left empty
+func (f formField) value() (any, error) {
+	switch f.kind {
+	case selectField:
+		if f.choice < 0 {
+			return nil, nil
+		}
+		return map[string]string{"id": f.meta.AllowedValues[f.choice].ID}, nil
+	case multiField:
+		values := make([]map[string]string, 0)
+		for _, allowed := range f.meta.AllowedValues {
+			if f.picked[allowed.ID] {
+				values = append(values, map[string]string{"id": allowed.ID})
+			}
+		}
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values, nil
+	case userField:
+		user := f.chosen
+		if user == nil && len(f.users) > 0 {
+			user = &f.users[f.cursor]
+		}
+		switch {
+		case user != nil && user.AccountID != "":
+			return map[string]string{"accountId": user.AccountID}, nil
+		// jira server goes by username
+		case user != nil:
+			return map[string]string{"name": user.Name}, nil
+		case strings.TrimSpace(f.input.Value()) != "":
+			return nil, errors.New("nobody matches that")
+		}
+		return nil, nil
+	}
+
+	text := strings.TrimSpace(f.input.Value())
+	if text == "" {
+		return nil, nil
+	}
+	switch {
+	case f.meta.Schema.Type == "number":
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, errors.New("that's not a number")
+		}
+		return number, nil
+	case f.meta.Schema.Type == "date":
+		if _, err := time.Parse(time.DateOnly, text); err != nil {
+			return nil, errors.New("dates look like 2024-01-31")
+		}
+	case f.meta.Schema.Type == "array":
+		return strings.Fields(text), nil
+	case f.meta.Schema.System == "description":
+		return m2j.MDToJira(text), nil
+	}
+	return text, nil
+}
+
+// whether the field looks things up as it's typed into: people to assign,
+// or labels already in use
+func (f formField) suggests() bool {
+	return f.kind == userField || (f.kind == textField && f.meta.Schema.System == "labels")
+}
+
+// what to look up: the whole search for people, the word being typed for
+// labels
+func (f formField) query() string {
+	text := f.input.Value()
+	if f.kind == userField {
+		return strings.TrimSpace(text)
+	}
+	words := strings.Fields(text)
+	if len(words) == 0 || strings.HasSuffix(text, " ") {
+		return ""
+	}
+	return words[len(words)-1]
+}
+
+// there's a list of suggestions showing, which takes ↑/↓ and tab
+func (f formField) completing() bool {
+	return len(f.users) > 0 || len(f.suggestions) > 0
+}
+
+// found what the field's query could be. labels already typed aren't
+// suggested again.
+func (f *formField) suggest(users []jira.User, labels []string) {
+	words := strings.Fields(f.input.Value())
+	f.users = users[:min(len(users), maxUserResults)]
+	f.suggestions = slices.DeleteFunc(labels, func(label string) bool { return slices.Contains(words, label) })
+	f.cursor = 0
+}
+
+func (f *formField) update(msg tea.KeyMsg) tea.Cmd {
+	switch f.kind {
+	case selectField:
+		switch msg.Type {
+		case tea.KeyLeft:
+			f.cycle(-1)
+		case tea.KeyRight:
+			f.cycle(1)
+		}
+		return nil
+	case multiField:
+		switch {
+		case msg.Type == tea.KeyLeft:
+			f.cursor = max(f.cursor-1, 0)
+		case msg.Type == tea.KeyRight:
+			f.cursor = min(f.cursor+1, len(f.meta.AllowedValues)-1)
+		case key.Matches(msg, keymap.Keys.Toggle):
+			// copied so the model we came from keeps what it had
+			f.picked = maps.Clone(f.picked)
+			id := f.meta.AllowedValues[f.cursor].ID
+			f.picked[id] = !f.picked[id]
+		}
+		return nil
+	}
+
+	if f.completing() {
+		count := max(len(f.users), len(f.suggestions))
+		switch {
+		case msg.Type == tea.KeyUp:
+			f.cursor = max(f.cursor-1, 0)
+			return nil
+		case msg.Type == tea.KeyDown:
+			f.cursor = min(f.cursor+1, count-1)
+			return nil
+		case key.Matches(msg, keymap.Keys.NextField):
+			f.complete()
+			return nil
+		}
+	}
+
+	before := f.input.Value()
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	// what was found was for what was typed before, so picking from it
+	// could pick the wrong one
+	if f.input.Value() != before {
+		f.chosen = nil
+		f.users, f.suggestions = nil, nil
+	}
+	return cmd
+}
+
+// take the suggestion under the cursor
+func (f *formField) complete() {
+	if f.kind == userField {
+		user := f.users[f.cursor]
+		f.chosen = &user
+		f.input.SetValue(user.DisplayName)
+	} else {
+		text := strings.TrimRight(f.input.Value(), " ")
+		text = strings.TrimSuffix(text, f.query())
+		f.input.SetValue(text + f.suggestions[f.cursor] + " ")
+	}
+	f.input.CursorEnd()
+	f.users, f.suggestions, f.cursor = nil, nil, 0
+}
+
+// step through the allowed values. optional fields can go back to none,
+// required ones wrap around.
+func (f *formField) cycle(step int) {
+	n := len(f.meta.AllowedValues)
+	switch {
+	case n == 0:
+	case f.meta.Required && f.choice < 0 && step > 0:
+		f.choice = 0
+	case f.meta.Required && f.choice < 0:
+		f.choice = n - 1
+	case f.meta.Required:
+		f.choice = (f.choice + step + n) % n
+	default:
+		f.choice = (f.choice+1+step+n+1)%(n+1) - 1
+	}
+}
+
+// keep what was in old, as far as it still fits
+func (f *formField) carryOver(old formField) {
+	if old.kind != f.kind {
+		return
+	}
+	switch f.kind {
+	case textField:
+		f.input.SetValue(old.input.Value())
+	case userField:
+		f.input.SetValue(old.input.Value())
+		f.chosen = old.chosen
+	case selectField:
+		if old.choice >= 0 {
+			id := old.meta.AllowedValues[old.choice].ID
+			f.choice = slices.IndexFunc(f.meta.AllowedValues, func(v AllowedValue) bool { return v.ID == id })
+		}
+	case multiField:
+		for _, allowed := range f.meta.AllowedValues {
+			f.picked[allowed.ID] = old.picked[allowed.ID]
+		}
+	}
+}
+
+func (f *formField) focus() tea.Cmd {
+	f.focused = true
+	if f.kind == textField || f.kind == userField {
+		return f.input.Focus()
+	}
+	return nil
+}
+
+func (f *formField) blur() {
+	f.focused = false
+	f.input.Blur()
+	f.users, f.suggestions = nil, nil
+}
+
+// the field's label, padded to labelWidth, then whatever's in it, with
+// what's wrong with it underneath
+func (f formField) view(labelWidth int, accentColor lipgloss.Color, width int) string {
+	label := f.meta.Name
+	if f.meta.Required {
+		label += "*"
+	}
+	label = lipgloss.NewStyle().Width(labelWidth).Render(label)
+	if f.focused {
+		label = pickerCursorStyle.Foreground(accentColor).Render("> " + label)
+	} else {
+		label = "  " + label
+	}
+
+	rendered := lipgloss.JoinHorizontal(lipgloss.Top, label, " ", f.valueView(max(width-labelWidth-3, 1)))
+	if f.err == "" {
+		return rendered
+	}
+	return rendered + "\n" + jqlErrorStyle.Width(width).PaddingLeft(labelWidth+3).Render(f.err)
+}
+
+// whatever's in the field, with any suggestions underneath
+func (f formField) valueView(width int) string {
+	switch f.kind {
+	case selectField:
+		value := jqlHintStyle.Render("none")
+		if f.choice >= 0 {
+			allowed := f.meta.AllowedValues[f.choice]
+			value = cmp.Or(allowed.Name, allowed.Value)
+		}
+		if f.focused {
+			value = "‹ " + value + " ›"
+		}
+		return value
+	case multiField:
+		options := make([]string, 0, len(f.meta.AllowedValues))
+		for i, allowed := range f.meta.AllowedValues {
+			option := "[ ] "
+			if f.picked[allowed.ID] {
+				option = "[x] "
+			}
+			option += cmp.Or(allowed.Name, allowed.Value)
+			if f.focused && i == f.cursor {
+				option = pickerCursorStyle.Underline(true).Render(option)
+			}
+			options = append(options, option)
+		}
+		return lipgloss.NewStyle().Width(width).Render(strings.Join(options, "  "))
+	}
+
+	lines := []string{f.input.View()}
+	for i, user := range f.users {
+		lines = append(lines, suggestionLine(user.DisplayName, i == f.cursor))
+	}
+	if len(f.suggestions) > 0 {
+		options := make([]string, 0, len(f.suggestions))
+		for i, label := range f.suggestions {
+			options = append(options, suggestionLine(label, i == f.cursor))
+		}
+		lines = append(lines, lipgloss.NewStyle().Width(width).Render(strings.Join(options, " ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func suggestionLine(suggestion string, picked bool) string {
+	if picked {
+		return pickerCursorStyle.Render("> " + suggestion)
+	}
+	return jqlHintStyle.Render("  " + suggestion)
+}
+
+// typing paused in a field that suggests things. seq says which pause, so
+// the view can tell whether anything's been typed since.
+type suggestTickEvent struct {
+	view int
+	seq  int
+}
+
+// what a field's query could be
+type suggestionsEvent struct {
+	view   int
+	seq    int
+	users  []jira.User
+	labels []string
+}
+
+// wait for typing to pause before looking anything up
+func waitToSuggest(view int, seq int) tea.Cmd {
+	return tea.Tick(suggestDelay, func(time.Time) tea.Msg { return suggestTickEvent{view: view, seq: seq} })
+}
+
+func fetchSuggestions(jiraData Backend, view int, seq int, field formField, scope UserScope) tea.Cmd {
+	query := field.query()
+	if query == "" {
+		return nil
+	}
+	return request(func() (tea.Msg, error) {
+		if field.kind == userField {
+			// only the assignee has to be someone who can be assigned
+			if field.id != "assignee" {
+				scope = UserScope{}
+			}
+			users, err := jiraData.FindUsers(query, scope)
+			return suggestionsEvent{view: view, seq: seq, users: users}, err
+		}
+		labels, err := jiraData.SuggestLabels(query)
+		return suggestionsEvent{view: view, seq: seq, labels: labels}, err
+	})
+}
+
+// what jira said about each field it didn't like, and whatever else it
+// said that isn't about one field
+func fieldErrors(err error) (map[string]string, []string) {
+	jiraErr := &jira.Error{}
+	if !errors.As(err, &jiraErr) {
+		return nil, []string{err.Error()}
+	}
+	return jiraErr.Errors, slices.Clone(jiraErr.ErrorMessages)
+}
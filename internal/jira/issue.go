@@ -0,0 +1,627 @@
+package jira
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+	"github.com/guppy0130/j2m"
+)
+
+const detailsWidth = 36 // the right hand panel, borders included
+
+// the glamour style descriptions and comments are drawn in, "dark" or
+// "light"
+var MarkdownTheme = "dark"
+
+var lightBorder = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("250"))
+
+/*
+ * summary
+ * desc                | details
+ * comments/worklogs   |
+ */
+type IssueView struct {
+	id          int
+	jiraData    Backend
+	issueID     string // key or id, whatever we were opened with
+	issue       *jira.Issue
+	accentColor lipgloss.Color
+	width       int
+	height      int
+	viewport    viewport.Model
+
+	selected       int    // which comment is picked, -1 for none
+	commentOffsets []int  // the line each comment starts on, for scrolling to it
+	confirming     bool   // asking whether to delete the picked comment
+	notice         string // how the last change went, or what keys do what
+	left           string // description and the tab showing, which only change with the issue
+	tab            issueTab
+	worklogs       []jira.WorklogRecord // nil until the worklog tab's been shown
+
+	editMeta    map[string]FieldMeta // what the fields can be changed to, nil until asked for
+	fieldMode   bool                 // picking a field in the details pane
+	fieldCursor int                  // which of the editable fields is picked
+	editing     bool                 // the picked field's value is swapped for editor
+	editor      formField
+	saving      bool // waiting on jira to take the change
+	suggestSeq  int  // the last pause in typing, so older suggestions are dropped
+}
+
+// an issue arrived for a view
+type issueEvent struct {
+	view  int
+	issue *jira.Issue
+}
+
+// the user picked an issue
+type OpenIssueMsg struct {
+	IssueID string
+}
+
+func NewIssueView(jiraData Backend, issueID string, accentColor lipgloss.Color, width int, height int) IssueView {
+	return IssueView{
+		id:          nextViewID(),
+		jiraData:    jiraData,
+		issueID:     issueID,
+		accentColor: accentColor,
+		width:       width,
+		height:      height,
+		viewport:    viewport.New(width, height),
+		selected:    -1,
+	}
+}
+
+func (i IssueView) Init() tea.Cmd {
+	return i.fetch()
+}
+
+func (i IssueView) fetch() tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		issue, err := i.jiraData.GetIssue(i.issueID)
+		return issueEvent{view: i.id, issue: issue}, err
+	})
+}
+
+func (i IssueView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		i.width = msg.Width
+		i.height = msg.Height
+		i.render()
+		return i, nil
+
+	case issueEvent:
+		if msg.view != i.id {
+			return i, nil
+		}
+		i.issue = msg.issue
+		i.render()
+		return i, nil
+
+	case AccentColorMsg:
+		i.accentColor = msg.Color
+		i.render()
+		return i, nil
+
+	case KeysChangedMsg:
+		i.viewport.KeyMap = scrollKeyMap()
+		return i, nil
+
+	case ThemeChangedMsg:
+		i.render()
+		return i, nil
+
+	case IssueUpdatedMsg:
+		if i.issue != nil && i.issue.ID == msg.Issue.ID {
+			i.issue = msg.Issue
+			i.selected = min(i.selected, len(i.comments())-1)
+			i.notice = ""
+			// a saved field goes back to showing its value
+			if i.saving {
+				i.editing, i.saving = false, false
+			}
+			if i.fieldMode {
+				i.notice = fieldModeHint()
+			}
+			i.render()
+			// work may have been logged, so what's shown could be stale
+			if i.worklogs != nil {
+				return i, i.fetchWorklogs()
+			}
+		}
+		return i, nil
+
+	case worklogsEvent:
+		if msg.view != i.id {
+			return i, nil
+		}
+		i.worklogs = msg.worklogs
+		if i.worklogs == nil {
+			i.worklogs = make([]jira.WorklogRecord, 0)
+		}
+		i.render()
+		return i, nil
+
+	case editMetaEvent:
+		if msg.view != i.id {
+			return i, nil
+		}
+		i.editMeta = msg.fields
+		if len(i.editableFieldIDs()) == 0 {
+			i.fieldMode = false
+			i.setNotice("none of these fields can be changed")
+		} else if i.fieldMode {
+			i.setNotice(fieldModeHint())
+		}
+		return i, nil
+
+	case fieldRejectedEvent:
+		if msg.view == i.id && i.editing {
+			i.rejectField(msg.err)
+		}
+		return i, nil
+
+	case suggestTickEvent:
+		if msg.view == i.id && msg.seq == i.suggestSeq && i.editing {
+			return i, fetchSuggestions(i.jiraData, i.id, msg.seq, i.editor, UserScope{IssueKey: i.issue.Key})
+		}
+		return i, nil
+
+	case suggestionsEvent:
+		if msg.view == i.id && msg.seq == i.suggestSeq && i.editing {
+			i.editor.suggest(msg.users, msg.labels)
+			i.layout()
+		}
+		return i, nil
+
+	case keyCopiedEvent:
+		if msg.view != i.id {
+			return i, nil
+		}
+		if msg.err != nil {
+			i.setNotice("couldn't copy " + msg.key + ": " + msg.err.Error())
+		} else {
+			i.setNotice("copied " + msg.key)
+		}
+		return i, nil
+
+	case commentEditedEvent:
+		if msg.view != i.id {
+			return i, nil
+		}
+		var cmd tea.Cmd
+		switch {
+		case msg.err != nil:
+			i.notice = msg.err.Error()
+		case strings.TrimSpace(msg.body) == "":
+			i.notice = "nothing written, so nothing saved"
+		default:
+			i.notice = "saving comment..."
+			cmd = i.saveComment(msg.commentID, msg.body)
+		}
+		i.render()
+		return i, cmd
+
+	case tea.KeyMsg:
+		if i.issue == nil {
+			break
+		}
+		switch {
+		case i.confirming:
+			return i.confirmDelete(msg)
+		case i.editing:
+			return i.updateEditor(msg)
+		case i.fieldMode:
+			return i.updateFieldMode(msg)
+		}
+		if action, ok := keymap.Keys.Match(msg, keymap.Issue); ok {
+			if view, cmd, done := i.Do(action); done {
+				return view, cmd
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	i.viewport, cmd = i.viewport.Update(msg)
+	return i, cmd
+}
+
+func (i IssueView) Do(action string) (tea.Model, tea.Cmd, bool) {
+	// there's nothing to act on yet, or something's being done to it
+	if i.issue == nil || i.confirming || i.editing || i.fieldMode {
+		return i, nil, false
+	}
+	// picking or changing a comment brings them back into view
+	if i.tab == worklogTab && slices.Contains([]string{"next_comment", "prev_comment", "edit_comment", "delete_comment"}, action) {
+		i.tab = commentsTab
+		i.render()
+	}
+	switch action {
+	case "up":
+		i.viewport.ScrollUp(1)
+	case "down":
+		i.viewport.ScrollDown(1)
+	case "page_up":
+		i.viewport.PageUp()
+	case "page_down":
+		i.viewport.PageDown()
+	case "top":
+		i.viewport.GotoTop()
+	case "bottom":
+		i.viewport.GotoBottom()
+	case "edit_field":
+		view, cmd := i.startFieldMode()
+		return view, cmd, true
+	case "next_tab":
+		view, cmd := i.switchTab()
+		return view, cmd, true
+	case "log_work":
+		issueID := i.issue.Key
+		return i, func() tea.Msg { return LogWorkMsg{IssueID: issueID} }, true
+	case "transition":
+		issueID := i.issueID
+		return i, func() tea.Msg { return TransitionIssueMsg{IssueID: issueID} }, true
+	case "assign_to_me":
+		if assignee, self := i.issue.Fields.Assignee, i.jiraData.Self(); assignee != nil && assignee.AccountID == self.AccountID && assignee.Name == self.Name {
+			i.setNotice("it's already yours")
+			return i, nil, true
+		}
+		i.setNotice("assigning to you...")
+		return i, i.assignToSelf(), true
+	case "copy_key":
+		return i, i.copyKey(), true
+	case "next_comment":
+		i.selectComment(min(i.selected+1, len(i.comments())-1))
+	case "prev_comment":
+		i.selectComment(max(i.selected-1, min(0, len(i.comments())-1)))
+	case "comment":
+		return i, i.editComment("", ""), true
+	case "edit_comment":
+		comment, ok := i.ownSelectedComment()
+		if !ok {
+			i.setNotice(i.cantChangeComment(comment))
+			return i, nil, true
+		}
+		return i, i.editComment(comment.ID, j2m.JiraToMD(comment.Body)), true
+	case "delete_comment":
+		comment, ok := i.ownSelectedComment()
+		if !ok {
+			i.setNotice(i.cantChangeComment(comment))
+			return i, nil, true
+		}
+		i.confirming = true
+		i.setNotice("delete this comment? (y/n)")
+	default:
+		return i, nil, false
+	}
+	return i, nil, true
+}
+
+// the issue's key went onto the clipboard, or didn't
+type keyCopiedEvent struct {
+	view int
+	key  string
+	err  error
+}
+
+// the clipboard is another program on some systems, so it's off the main
+// loop
+func (i IssueView) copyKey() tea.Cmd {
+	id, issueKey := i.id, i.issue.Key
+	return func() tea.Msg {
+		return keyCopiedEvent{view: id, key: issueKey, err: clipboard.WriteAll(issueKey)}
+	}
+}
+
+// y deletes the picked comment, anything else leaves it be
+func (i IssueView) confirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	i.confirming = false
+	comment, ok := i.ownSelectedComment()
+	if msg.String() != "y" || !ok {
+		i.setNotice("")
+		return i, nil
+	}
+	i.setNotice("deleting comment...")
+	return i, i.deleteComment(comment.ID)
+}
+
+func (i IssueView) cantChangeComment(comment *jira.Comment) string {
+	if comment == nil {
+		return "pick a comment first (" + keymap.Keys.NextComment.Help().Key + "/" + keymap.Keys.PrevComment.Help().Key + ")"
+	}
+	return "that's " + comment.Author.DisplayName + "'s comment"
+}
+
+func (i *IssueView) setNotice(notice string) {
+	i.notice = notice
+	i.render()
+}
+
+// pick a comment and scroll to it
+func (i *IssueView) selectComment(selected int) {
+	i.selected = selected
+	i.render()
+	if selected >= 0 && selected < len(i.commentOffsets) {
+		i.viewport.SetYOffset(i.commentOffsets[selected])
+	}
+}
+
+// a delete is waiting on y/n, or a field's being picked or edited, so
+// nothing else should take the keys. while a field's being saved the app's
+// keys work, so a save that failed can be retried or dismissed.
+func (i IssueView) Capturing() bool {
+	return i.confirming || (i.fieldMode && !i.saving)
+}
+
+// the issue's key, for headers and the statusbar
+func (i IssueView) Title() string {
+	if i.issue != nil {
+		return i.issue.Key
+	}
+	return i.issueID
+}
+
+func (i IssueView) View() string {
+	if i.issue == nil {
+		return "loading " + i.issueID + "..."
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, i.renderSummary(), i.viewport.View())
+}
+
+func (i IssueView) renderSummary() string {
+	summary := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(i.accentColor).
+		Width(i.width).
+		Render(fmt.Sprintf("%s: %s", i.issue.Key, i.issue.Fields.Summary))
+	if i.notice == "" {
+		return summary
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, summary, jqlHintStyle.Width(i.width).Render(i.notice))
+}
+
+// lay the issue out into the viewport, keeping the scroll position
+func (i *IssueView) render() {
+	if i.issue == nil {
+		return
+	}
+	summaryHeight := lipgloss.Height(i.renderSummary())
+	offset := i.viewport.YOffset
+	i.viewport = viewport.New(i.width, max(i.height-summaryHeight, 1))
+	i.viewport.KeyMap = scrollKeyMap()
+	// layout clamps it once there's something to scroll through
+	i.viewport.YOffset = offset
+
+	// details get a fixed width, description and comments get the rest.
+	// lipgloss widths don't count the border, hence the -2s.
+	leftWidth := max(i.width-detailsWidth, 20)
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(MarkdownTheme),
+		glamour.WithWordWrap(leftWidth-4),
+	)
+	if err != nil {
+		slog.Error("couldn't make a renderer", "err", err)
+	}
+
+	border := i.paneBorder()
+	description := border.Width(leftWidth - 2).Render(renderJira(renderer, i.issue.Fields.Description))
+	tab, offsets := i.renderComments(renderer, leftWidth-2)
+	if i.tab == worklogTab {
+		tab, offsets = i.renderWorklogs(leftWidth-2), nil
+	}
+	leftHalf := lipgloss.JoinVertical(lipgloss.Left, description, border.Width(leftWidth-2).Render(tab))
+	// comments start below the description and the comments box's top border
+	i.commentOffsets = make([]int, 0, len(offsets))
+	for _, offset := range offsets {
+		i.commentOffsets = append(i.commentOffsets, lipgloss.Height(description)+1+offset)
+	}
+	i.left = leftHalf
+	i.layout()
+}
+
+// put the details pane next to the rest, keeping the scroll position. only
+// the details pane changes while fields are edited, so that's all this
+// redraws.
+func (i *IssueView) layout() {
+	details, top, bottom := i.renderDetails()
+	rightHalf := i.paneBorder().Width(detailsWidth - 2).Render(details)
+	offset := i.viewport.YOffset
+	i.viewport.SetContent(lipgloss.JoinHorizontal(lipgloss.Top, i.left, rightHalf))
+	// keep the picked field in sight, counting the pane's top border
+	if i.fieldMode {
+		offset = min(offset, top+1)
+		offset = max(offset, bottom+1-i.viewport.Height)
+	}
+	i.viewport.SetYOffset(offset)
+}
+
+func (i IssueView) paneBorder() lipgloss.Style {
+	return lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(i.accentColor)
+}
+
+// the comments, and the line each one starts on
+func (i IssueView) renderComments(renderer *glamour.TermRenderer, width int) (string, []int) {
+	s := strings.Builder{}
+	s.WriteString(i.renderTabs())
+	offsets := make([]int, 0)
+
+	if len(i.comments()) == 0 {
+		s.WriteString("\nno comments")
+		return s.String(), offsets
+	}
+	for n, comment := range i.comments() {
+		c := strings.Builder{}
+		// handle author rendering
+		c.WriteString(fmt.Sprintf("%s, at %s", comment.Author.DisplayName, formatJiraTime(comment.Created)))
+		// and if there's an update, indicate changes
+		if comment.Created != comment.Updated {
+			c.WriteString(fmt.Sprintf("\n(last updated by %s at %s)", comment.UpdateAuthor.DisplayName, formatJiraTime(comment.Updated)))
+		}
+		// write the body of the comment
+		c.WriteString("\n")
+		c.WriteString(renderJira(renderer, comment.Body))
+		s.WriteString("\n")
+		offsets = append(offsets, lipgloss.Height(s.String())-1)
+		style := lightBorder
+		if n == i.selected {
+			style = style.BorderForeground(i.accentColor)
+		}
+		s.WriteString(style.Width(width - 2).Render(strings.TrimSpace(c.String())))
+	}
+	return s.String(), offsets
+}
+
+// the right hand panel, and the lines the picked field starts and ends on
+func (i IssueView) renderDetails() (string, int, int) {
+	f := i.issue.Fields
+	label := lipgloss.NewStyle().Bold(true)
+	lines := []string{label.Render("Details")}
+	picked, top, bottom := i.pickedField(), 0, 0
+
+	// id is the field's, for the ones that can be changed
+	row := func(id string, name string, value string) {
+		if value == "" {
+			value = "none"
+		}
+		if id != "" && id == picked {
+			top = len(lines)
+			lines = append(lines, pickerCursorStyle.Foreground(i.accentColor).Render("> "+name))
+		} else {
+			lines = append(lines, label.Render(name))
+		}
+		if i.editing && id == i.editor.id {
+			value = i.editor.valueView(detailsWidth - 4)
+		}
+		lines = append(lines, strings.Split(lipgloss.NewStyle().PaddingLeft(2).Render(value), "\n")...)
+		if i.editing && id == i.editor.id && i.editor.err != "" {
+			lines = append(lines, strings.Split(jqlErrorStyle.Width(detailsWidth-2).PaddingLeft(2).Render(i.editor.err), "\n")...)
+		}
+		if id != "" && id == picked {
+			bottom = len(lines)
+		}
+	}
+	row("assignee", "Assignee", displayName(f.Assignee))
+	row("", "Reporter", displayName(f.Reporter))
+	if f.Status != nil {
+		row("", "Status", f.Status.Name)
+	}
+	if f.Resolution != nil {
+		row("", "Resolution", f.Resolution.Name)
+	}
+	priority := ""
+	if f.Priority != nil {
+		priority = f.Priority.Name
+	}
+	// editable fields get a row even when they're empty, so they can be
+	// picked
+	if priority != "" || i.fieldMode {
+		row("priority", "Priority", priority)
+	}
+	row("", "Type", f.Type.Name)
+	row("labels", "Labels", strings.Join(f.Labels, ", "))
+	components := make([]string, 0)
+	for _, component := range f.Components {
+		components = append(components, component.Name)
+	}
+	if len(components) > 0 || i.fieldMode {
+		row("components", "Components", strings.Join(components, ", "))
+	}
+	if f.Sprint != nil {
+		row("", "Sprint", f.Sprint.Name)
+	}
+	versions := make([]string, 0)
+	for _, version := range f.FixVersions {
+		versions = append(versions, version.Name)
+	}
+	row("fixVersions", "Fix versions", strings.Join(versions, ", "))
+	due := ""
+	if !time.Time(f.Duedate).IsZero() {
+		due = time.Time(f.Duedate).Format(time.DateOnly)
+	}
+	if due != "" || i.fieldMode {
+		row("duedate", "Due date", due)
+	}
+	points, hasPoints := storyPoints(i.issue)
+	pointsID := i.storyPointsFieldID()
+	if hasPoints || (i.fieldMode && pointsID != "") {
+		value := ""
+		if hasPoints {
+			value = fmt.Sprint(points)
+		}
+		row(pointsID, "Story points", value)
+	}
+	return strings.Join(lines, "\n"), top, bottom
+}
+
+// where the issue keeps its story points, going by editmeta or by the
+// names it came with
+func (i IssueView) storyPointsFieldID() string {
+	for id, meta := range i.editMeta {
+		if slices.Contains(storyPointsFieldNames, meta.Name) {
+			return id
+		}
+	}
+	for id, name := range i.issue.Names {
+		if slices.Contains(storyPointsFieldNames, name) {
+			return id
+		}
+	}
+	return ""
+}
+
+// story points live in a custom field whose id differs between instances,
+// so find it by name (the issue is fetched with expand=names)
+var storyPointsFieldNames = []string{"Story Points", "Story point estimate"}
+
+func storyPoints(issue *jira.Issue) (float64, bool) {
+	for id, name := range issue.Names {
+		if !slices.Contains(storyPointsFieldNames, name) {
+			continue
+		}
+		if points, ok := issue.Fields.Unknowns[id].(float64); ok {
+			return points, true
+		}
+	}
+	return 0, false
+}
+
+func displayName(user *jira.User) string {
+	if user == nil {
+		return "Unassigned"
+	}
+	return user.DisplayName
+}
+
+// jira wiki markup, converted to markdown and rendered for the terminal.
+// falls back to the raw markup if glamour isn't having it.
+func renderJira(renderer *glamour.TermRenderer, jiraContent string) string {
+	if renderer == nil {
+		return jiraContent
+	}
+	content, err := renderer.Render(j2m.JiraToMD(jiraContent))
+	if err != nil {
+		slog.Error("couldn't render", "err", err)
+		return jiraContent
+	}
+	return strings.Trim(content, "\n")
+}
+
+// comment timestamps are strings like 2024-01-15T09:30:00.000+0000
+func formatJiraTime(raw string) string {
+	t, err := time.Parse("2006-01-02T15:04:05.000-0700", raw)
+	if err != nil {
+		return raw
+	}
+	return t.Local().Format(time.RFC1123)
+}
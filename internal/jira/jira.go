@@ -2,49 +2,372 @@ package jira
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 
 	"github.com/andygrunwald/go-jira"
+	"github.com/guppy0130/go-jira-tui/internal/config"
 )
 
 // container for client + user
 type JiraData struct {
-	client jira.Client
+	client *jira.Client
 	user   *jira.User
+	host   string // the site's, which oauth requests don't go to directly
 }
 
-// get a client + user object
-func NewJiraData(email string, token string, url string) JiraData {
-	jiraAuthBasic := jira.BasicAuthTransport{
-		Username: email,
-		Password: token,
+// get a client + user object, signing in the way the profile says to
+func NewJiraData(profile config.Profile, approve Approver) (JiraData, error) {
+	profile, err := profile.LoadToken()
+	if err != nil {
+		return JiraData{}, err
+	}
+	httpClient, baseURL, err := authClient(profile, approve)
+	if err != nil {
+		return JiraData{}, fmt.Errorf("signing into %s: %w", profile.Url, err)
+	}
+	jiraClient, err := jira.NewClient(httpClient, baseURL)
+	if err != nil {
+		return JiraData{}, fmt.Errorf("creating client for %s: %w", profile.Url, err)
+	}
+	jiraUser, resp, err := jiraClient.User.GetSelf()
+	if err != nil {
+		return JiraData{}, newRequestError("signing in", resp, err)
+	}
+
+	host := jiraClient.GetBaseURL().Host
+	if u, err := url.Parse(profile.Url); err == nil {
+		host = u.Host
+	}
+	return JiraData{client: jiraClient, user: jiraUser, host: host}, nil
+}
+
+func (j JiraData) Self() *jira.User {
+	return j.user
+}
+
+func (j JiraData) Host() string {
+	return j.host
+}
+
+// a page of all the boards
+func (j JiraData) GetBoards(startAt int) (*jira.BoardsList, error) {
+	boards, resp, err := j.client.Board.GetAllBoards(&jira.BoardListOptions{
+		SearchOptions: jira.SearchOptions{StartAt: startAt, MaxResults: pageSize},
+	})
+	return boards, newRequestError("fetching boards", resp, err)
+}
+
+// a page of the sprints that belong to a board
+func (j JiraData) GetSprintsForBoard(boardID int, startAt int) (*jira.SprintsList, error) {
+	sprints, resp, err := j.client.Board.GetAllSprintsWithOptions(boardID, &jira.GetAllSprintsOptions{
+		SearchOptions: jira.SearchOptions{StartAt: startAt, MaxResults: pageSize},
+	})
+	return sprints, newRequestError(fmt.Sprintf("fetching sprints for board %d", boardID), resp, err)
+}
+
+// a page of the issues on a board. the agile api applies the board's filter
+// (and a kanban board's sub-filter), so this matches what the web UI shows.
+func (j JiraData) GetIssuesForBoard(board jira.Board, startAt int) (*IssuesPage, error) {
+	return j.getAgileIssues(
+		fmt.Sprintf("fetching issues for board %s", board.Name),
+		fmt.Sprintf("rest/agile/1.0/board/%d/issue", board.ID),
+		startAt,
+	)
+}
+
+// a page of the issues in a board's backlog
+func (j JiraData) GetBacklogForBoard(board jira.Board, startAt int) (*IssuesPage, error) {
+	return j.getAgileIssues(
+		fmt.Sprintf("fetching backlog for board %s", board.Name),
+		fmt.Sprintf("rest/agile/1.0/board/%d/backlog", board.ID),
+		startAt,
+	)
+}
+
+// the transitions an issue can take, expanded so we know which fields each
+// one needs and what they can be set to
+func (j JiraData) GetTransitions(issueID string) ([]Transition, error) {
+	op := fmt.Sprintf("fetching transitions for %s", issueID)
+	req, err := j.client.NewRequest(http.MethodGet, fmt.Sprintf("rest/api/2/issue/%s/transitions?expand=transitions.fields", issueID), nil)
+	if err != nil {
+		return nil, newRequestError(op, nil, err)
+	}
+	result := struct {
+		Transitions []Transition `json:"transitions"`
+	}{}
+	resp, err := j.client.Do(req, &result)
+	if err != nil {
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
+	}
+	return result.Transitions, nil
+}
+
+func (j JiraData) DoTransition(issueID string, transitionID string, fields map[string]string) error {
+	payload := map[string]any{"transition": map[string]string{"id": transitionID}}
+	if len(fields) > 0 {
+		values := make(map[string]any)
+		for field, id := range fields {
+			values[field] = map[string]string{"id": id}
+		}
+		payload["fields"] = values
+	}
+	resp, err := j.client.Issue.DoTransitionWithPayload(issueID, payload)
+	if err != nil {
+		return newRequestError(fmt.Sprintf("transitioning %s", issueID), resp, err)
+	}
+	// nothing to decode, so go-jira leaves the body to us
+	return resp.Body.Close()
+}
+
+// the projects we can see. createmeta only answers for one project at a
+// time since jira 9 (and on cloud), so the ones we can't make anything in
+// turn up without issue types once they're picked.
+func (j JiraData) GetCreateMeta() ([]CreateMetaProject, error) {
+	op := "fetching projects"
+	req, err := j.client.NewRequest(http.MethodGet, "rest/api/2/project", nil)
+	if err != nil {
+		return nil, newRequestError(op, nil, err)
+	}
+	// decoded on their own, since the projects' issueTypes are every type
+	// they have rather than the ones that can be made
+	result := make([]struct {
+		ID   string `json:"id"`
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	}, 0)
+	resp, err := j.client.Do(req, &result)
+	if err != nil {
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
+	}
+	projects := make([]CreateMetaProject, 0, len(result))
+	for _, project := range result {
+		projects = append(projects, CreateMetaProject{ID: project.ID, Key: project.Key, Name: project.Name})
+	}
+	return projects, nil
+}
+
+func (j JiraData) GetCreateIssueTypes(projectKey string) ([]CreateMetaIssueType, error) {
+	return getCreateMetaPages[CreateMetaIssueType](
+		j,
+		fmt.Sprintf("fetching issue types for %s", projectKey),
+		fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes", url.PathEscape(projectKey)),
+	)
+}
+
+func (j JiraData) GetCreateFields(projectKey string, issueTypeID string) (map[string]FieldMeta, error) {
+	metas, err := getCreateMetaPages[struct {
+		FieldID string `json:"fieldId"`
+		FieldMeta
+	}](
+		j,
+		fmt.Sprintf("fetching fields for %s", projectKey),
+		fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes/%s", url.PathEscape(projectKey), url.PathEscape(issueTypeID)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]FieldMeta, len(metas))
+	for _, meta := range metas {
+		fields[meta.FieldID] = meta.FieldMeta
+	}
+	return fields, nil
+}
+
+// every page of one of the createmeta endpoints. server calls what's on a
+// page values, cloud calls it issueTypes or fields.
+func getCreateMetaPages[T any](j JiraData, op string, endpoint string) ([]T, error) {
+	all := make([]T, 0)
+	for {
+		req, err := j.client.NewRequest(http.MethodGet, fmt.Sprintf("%s?startAt=%d&maxResults=%d", endpoint, len(all), pageSize), nil)
+		if err != nil {
+			return nil, newRequestError(op, nil, err)
+		}
+		page := struct {
+			Values     []T  `json:"values"`
+			IssueTypes []T  `json:"issueTypes"`
+			Fields     []T  `json:"fields"`
+			Total      int  `json:"total"`
+			IsLast     bool `json:"isLast"`
+		}{}
+		resp, err := j.client.Do(req, &page)
+		if err != nil {
+			return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
+		}
+		values := slices.Concat(page.Values, page.IssueTypes, page.Fields)
+		all = append(all, values...)
+		if page.IsLast || len(values) == 0 || len(all) >= page.Total {
+			return all, nil
+		}
+	}
+}
+
+// go-jira hands back the raw error here, so it's decoded to get at which
+// fields jira didn't like
+func (j JiraData) CreateIssue(fields map[string]any) (*jira.Issue, error) {
+	issue, resp, err := j.client.Issue.Create(&jira.Issue{Fields: &jira.IssueFields{Unknowns: fields}})
+	if err != nil {
+		return nil, newRequestError("creating issue", resp, jira.NewJiraError(resp, err))
 	}
-	jiraClient, err := jira.NewClient(jiraAuthBasic.Client(), url)
+	return issue, nil
+}
+
+func (j JiraData) GetEditMeta(issueID string) (map[string]FieldMeta, error) {
+	op := fmt.Sprintf("fetching editable fields of %s", issueID)
+	req, err := j.client.NewRequest(http.MethodGet, fmt.Sprintf("rest/api/2/issue/%s/editmeta", issueID), nil)
 	if err != nil {
-		panic(err)
+		return nil, newRequestError(op, nil, err)
 	}
-	jiraUser, _, err := jiraClient.User.GetSelf()
+	result := struct {
+		Fields map[string]FieldMeta `json:"fields"`
+	}{}
+	resp, err := j.client.Do(req, &result)
 	if err != nil {
-		panic(err)
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
 	}
+	return result.Fields, nil
+}
 
-	return JiraData{client: *jiraClient, user: jiraUser}
+// like CreateIssue, go-jira hands back the raw error
+func (j JiraData) UpdateIssue(issueID string, fields map[string]any) error {
+	resp, err := j.client.Issue.UpdateIssue(issueID, map[string]any{"fields": fields})
+	if err != nil {
+		return newRequestError(fmt.Sprintf("updating %s", issueID), resp, jira.NewJiraError(resp, err))
+	}
+	// nothing to decode, so go-jira leaves the body to us
+	return resp.Body.Close()
 }
 
-// list of all the boards
-func (j JiraData) GetBoards() *jira.BoardsList {
-	boards, _, err := j.client.Board.GetAllBoards(&jira.BoardListOptions{})
+// go-jira's User.Find only searches everyone, by query, which server
+// rejects; it wants username, which matches names and emails all the same
+func (j JiraData) FindUsers(query string, scope UserScope) ([]jira.User, error) {
+	op := "finding users"
+	params := url.Values{"maxResults": {"10"}}
+	if j.user.AccountID != "" {
+		params.Set("query", query)
+	} else {
+		params.Set("username", query)
+	}
+	endpoint := "rest/api/2/user/search"
+	switch {
+	case scope.IssueKey != "":
+		endpoint = "rest/api/2/user/assignable/search"
+		params.Set("issueKey", scope.IssueKey)
+	case scope.Project != "":
+		endpoint = "rest/api/2/user/assignable/search"
+		params.Set("project", scope.Project)
+	}
+	req, err := j.client.NewRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, newRequestError(op, nil, err)
+	}
+	users := make([]jira.User, 0)
+	resp, err := j.client.Do(req, &users)
 	if err != nil {
-		panic(err)
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
 	}
-	return boards
+	return users, nil
 }
 
-// issues in a particular board
-func (j JiraData) GetIssuesForBoard(board jira.Board) []jira.Issue {
-	issues, _, err := j.client.Issue.Search(fmt.Sprintf("project = %s", board.Name), &jira.SearchOptions{})
+// jql's autocomplete knows every label in use, which go-jira doesn't have
+func (j JiraData) SuggestLabels(prefix string) ([]string, error) {
+	op := "suggesting labels"
+	req, err := j.client.NewRequest(http.MethodGet, "rest/api/2/jql/autocompletedata/suggestions?fieldName=labels&fieldValue="+url.QueryEscape(prefix), nil)
 	if err != nil {
-		panic(err)
+		return nil, newRequestError(op, nil, err)
+	}
+	result := struct {
+		Results []struct {
+			Value string `json:"value"`
+		} `json:"results"`
+	}{}
+	resp, err := j.client.Do(req, &result)
+	if err != nil {
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
+	}
+	labels := make([]string, 0, len(result.Results))
+	for _, r := range result.Results {
+		labels = append(labels, r.Value)
 	}
+	return labels, nil
+}
+
+func (j JiraData) AddComment(issueID string, body string) (*jira.Comment, error) {
+	comment, resp, err := j.client.Issue.AddComment(issueID, &jira.Comment{Body: body})
+	return comment, newRequestError(fmt.Sprintf("commenting on %s", issueID), resp, err)
+}
+
+func (j JiraData) UpdateComment(issueID string, commentID string, body string) (*jira.Comment, error) {
+	comment, resp, err := j.client.Issue.UpdateComment(issueID, &jira.Comment{ID: commentID, Body: body})
+	return comment, newRequestError(fmt.Sprintf("editing comment on %s", issueID), resp, err)
+}
+
+// go-jira doesn't hand back the response here, so there's no status code
+func (j JiraData) DeleteComment(issueID string, commentID string) error {
+	err := j.client.Issue.DeleteComment(issueID, commentID)
+	return newRequestError(fmt.Sprintf("deleting comment on %s", issueID), nil, err)
+}
+
+func (j JiraData) GetWorklogs(issueID string) ([]jira.WorklogRecord, error) {
+	op := fmt.Sprintf("fetching worklogs for %s", issueID)
+	worklog, resp, err := j.client.Issue.GetWorklogs(issueID)
+	if err != nil {
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
+	}
+	return worklog.Worklogs, nil
+}
+
+func (j JiraData) AddWorklog(issueID string, worklog jira.WorklogRecord, estimate RemainingEstimate) error {
+	_, resp, err := j.client.Issue.AddWorklogRecord(issueID, &worklog, jira.WithQueryOptions(estimate))
+	return newRequestError(fmt.Sprintf("logging work on %s", issueID), resp, err)
+}
+
+// agile endpoints page issues the same way search does, but go-jira doesn't
+// have them
+type agileIssuesResult struct {
+	Issues     []jira.Issue `json:"issues"`
+	StartAt    int          `json:"startAt"`
+	MaxResults int          `json:"maxResults"`
+	Total      int          `json:"total"`
+}
+
+func (j JiraData) getAgileIssues(op string, endpoint string, startAt int) (*IssuesPage, error) {
+	req, err := j.client.NewRequest(http.MethodGet, fmt.Sprintf("%s?startAt=%d&maxResults=%d", endpoint, startAt, pageSize), nil)
+	if err != nil {
+		return nil, newRequestError(op, nil, err)
+	}
+	result := new(agileIssuesResult)
+	resp, err := j.client.Do(req, result)
+	if err != nil {
+		return nil, newRequestError(op, resp, jira.NewJiraError(resp, err))
+	}
+	return &IssuesPage{Issues: result.Issues, StartAt: result.StartAt, Total: result.Total}, nil
+}
+
+// a board's columns and which statuses land in each
+func (j JiraData) GetBoardConfiguration(boardID int) (*jira.BoardConfiguration, error) {
+	configuration, resp, err := j.client.Board.GetBoardConfiguration(boardID)
+	return configuration, newRequestError(fmt.Sprintf("fetching configuration for board %d", boardID), resp, err)
+}
+
+// a page of the issues in a sprint
+func (j JiraData) GetIssuesForSprint(sprint jira.Sprint, startAt int) (*IssuesPage, error) {
+	return j.SearchIssues(fmt.Sprintf("sprint = %d", sprint.ID), startAt)
+}
+
+// a page of the issues matching some JQL
+func (j JiraData) SearchIssues(jql string, startAt int) (*IssuesPage, error) {
+	issues, resp, err := j.client.Issue.Search(jql, &jira.SearchOptions{StartAt: startAt, MaxResults: pageSize})
+	if err != nil {
+		return nil, newRequestError("searching issues", resp, err)
+	}
+	return &IssuesPage{Issues: issues, StartAt: startAt, Total: resp.Total}, nil
+}
 
-	return issues
+// a single issue, with all its fields. names are expanded so custom fields
+// can be found by name.
+func (j JiraData) GetIssue(issueID string) (*jira.Issue, error) {
+	issue, resp, err := j.client.Issue.Get(issueID, &jira.GetQueryOptions{Expand: "names"})
+	return issue, newRequestError(fmt.Sprintf("fetching issue %s", issueID), resp, err)
 }
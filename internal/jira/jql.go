@@ -0,0 +1,215 @@
+package jira
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+var (
+	jqlErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	jqlHintStyle  = lipgloss.NewStyle().Faint(true)
+)
+
+// a prompt for running any jql. earlier queries come back with up/down, and
+// if jira doesn't like the query, it says why underneath.
+type JQLPrompt struct {
+	jiraData  Backend
+	input     textinput.Model
+	history   []string // queries that worked, oldest first
+	recall    int      // where we are in history, len(history) if not browsing
+	draft     string   // what was typed before browsing history
+	err       error    // why jira rejected the last query
+	searching string   // the query jira's running, if any
+	failed    string   // the query that last failed, which the banner can retry
+	width     int
+}
+
+// jira ran the query, here's the first page
+type jqlResultEvent struct {
+	jql  string
+	page *IssuesPage
+}
+
+// jira didn't understand the query
+type jqlRejectedEvent struct {
+	jql string
+	err error
+}
+
+// jira couldn't run the query, for some reason other than the query
+type jqlFailedEvent struct {
+	jql     string
+	failure ErrorMsg
+}
+
+// the user ran a query, show its results
+type OpenSearchMsg struct {
+	JQL  string
+	Page *IssuesPage // the first page, already fetched
+}
+
+func NewJQLPrompt(jiraData Backend) JQLPrompt {
+	input := textinput.New()
+	input.Prompt = "jql> "
+	input.Placeholder = "project = TUI AND statusCategory != Done ORDER BY updated DESC"
+	return JQLPrompt{
+		jiraData: jiraData,
+		input:    input,
+		history:  make([]string, 0),
+	}
+}
+
+// start a fresh query
+func (p JQLPrompt) Open() (JQLPrompt, tea.Cmd) {
+	p.input.Reset()
+	p.recall = len(p.history)
+	p.draft = ""
+	p.err = nil
+	p.searching, p.failed = "", ""
+	return p, p.input.Focus()
+}
+
+// run jql straight away, as if it had been typed in
+func (p JQLPrompt) Search(jql string) (JQLPrompt, tea.Cmd) {
+	p, cmd := p.Open()
+	p.input.SetValue(jql)
+	p.searching = jql
+	return p, tea.Batch(cmd, p.search(jql))
+}
+
+func (p JQLPrompt) Focused() bool {
+	return p.input.Focused()
+}
+
+// the prompt hears about the query failing before the banner does, so it
+// can make way for the banner's retry
+func (p JQLPrompt) search(jql string) tea.Cmd {
+	cmd := request(func() (tea.Msg, error) {
+		page, err := p.jiraData.SearchIssues(jql, 0)
+		if reason, ok := rejected(err); ok {
+			return jqlRejectedEvent{jql: jql, err: reason}, nil
+		}
+		return jqlResultEvent{jql: jql, page: page}, err
+	})
+	return func() tea.Msg {
+		msg := cmd()
+		if failure, ok := msg.(ErrorMsg); ok {
+			return jqlFailedEvent{jql: jql, failure: failure}
+		}
+		return msg
+	}
+}
+
+func (p JQLPrompt) Update(msg tea.Msg) (JQLPrompt, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.input.Width = max(msg.Width-lipgloss.Width(p.input.Prompt)-1, 1)
+		return p, nil
+
+	// esc while searching gave up on the query, so what it found isn't
+	// wanted, unless it's the banner retrying one that failed
+	case jqlResultEvent:
+		if msg.jql != p.searching && msg.jql != p.failed {
+			return p, nil
+		}
+		p.searching, p.failed = "", ""
+		p.remember(msg.jql)
+		p.input.Blur()
+		return p, func() tea.Msg { return OpenSearchMsg{JQL: msg.jql, Page: msg.page} }
+
+	case jqlRejectedEvent:
+		if msg.jql != p.searching {
+			return p, nil
+		}
+		p.searching = ""
+		p.err = msg.err
+		return p, nil
+
+	// out of the way, so the banner's keys work
+	case jqlFailedEvent:
+		p.failed = msg.jql
+		if msg.jql == p.searching {
+			p.searching = ""
+			p.input.Blur()
+		}
+		return p, func() tea.Msg { return msg.failure }
+
+	case tea.KeyMsg:
+		if !p.Focused() {
+			return p, nil
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			p.searching = ""
+			p.input.Blur()
+			return p, nil
+		case key.Matches(msg, keymap.Keys.Enter):
+			jql := p.input.Value()
+			if jql == "" || p.searching != "" {
+				return p, nil
+			}
+			p.searching = jql
+			p.err = nil
+			return p, p.search(jql)
+		case msg.Type == tea.KeyUp:
+			p.browse(-1)
+			return p, nil
+		case msg.Type == tea.KeyDown:
+			p.browse(1)
+			return p, nil
+		}
+		// the query changed, so whatever jira said no longer applies
+		p.err = nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
+
+// step through history, coming back to the draft at the end
+func (p *JQLPrompt) browse(step int) {
+	if p.recall == len(p.history) {
+		p.draft = p.input.Value()
+	}
+	p.recall = min(max(p.recall+step, 0), len(p.history))
+	if p.recall == len(p.history) {
+		p.input.SetValue(p.draft)
+	} else {
+		p.input.SetValue(p.history[p.recall])
+	}
+	p.input.CursorEnd()
+}
+
+// queries that worked, most recent first
+func (p JQLPrompt) History() []string {
+	history := slices.Clone(p.history)
+	slices.Reverse(history)
+	return history
+}
+
+// most recent last, without repeats
+func (p *JQLPrompt) remember(jql string) {
+	p.history = append(slices.DeleteFunc(p.history, func(h string) bool { return h == jql }), jql)
+}
+
+func (p JQLPrompt) View() string {
+	lines := []string{p.input.View()}
+	switch {
+	case p.searching != "":
+		lines = append(lines, jqlHintStyle.Render("searching..."))
+	case p.err != nil:
+		lines = append(lines, jqlErrorStyle.Width(p.width).Render(p.err.Error()))
+	default:
+		lines = append(lines, jqlHintStyle.Render(strings.Join([]string{keymap.Does("search", keymap.Keys.Enter), "↑/↓: history", keymap.Does("cancel", keymap.Keys.Back)}, " • ")))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
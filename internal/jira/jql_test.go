@@ -0,0 +1,44 @@
+package jira
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// a search that failed gets out of the way of the banner, and retrying it
+// from there still shows what it found
+func TestJQLRetry(t *testing.T) {
+	fake, err := NewFakeBackend(Fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := NewJQLPrompt(fake).Open()
+	p.input.SetValue("project = TUI")
+	fake.FailNext(http.StatusServiceUnavailable)
+	p, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	p, cmd = p.Update(cmd())
+	msg := cmd()
+	failure, ok := msg.(ErrorMsg)
+	if !ok {
+		t.Fatalf("a failed search gave %v, want an ErrorMsg for the banner", msg)
+	}
+	if p.Focused() || strings.Contains(p.View(), "searching...") {
+		t.Errorf("the prompt is still focused or searching after the search failed:\n%s", p.View())
+	}
+
+	// r on the banner
+	p, cmd = p.Update(failure.Retry())
+	if cmd == nil {
+		t.Fatal("the retried search was dropped")
+	}
+	if opened, ok := cmd().(OpenSearchMsg); !ok || opened.JQL != "project = TUI" {
+		t.Errorf("the retried search gave %v, want its results opened", opened)
+	}
+	if history := p.History(); len(history) != 1 || history[0] != "project = TUI" {
+		t.Errorf("History() = %v, want the retried query in it", history)
+	}
+}
@@ -0,0 +1,376 @@
+package jira
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// what cards can be grouped into lanes by, in the order they're cycled
+// through. the empty one means no lanes.
+var swimlaneModes = []string{"", "assignee", "epic"}
+
+const minKanbanColumnWidth = 16
+
+var (
+	columnHeaderStyle = lipgloss.NewStyle().Bold(true)
+	wipExceededStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+	laneHeaderStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	cardDetailStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// issues as cards in the board's columns, like the board page in jira
+type KanbanView struct {
+	id            int
+	jiraData      Backend
+	boardID       int    // whose configuration has the columns
+	title         string // what we're showing, e.g. the sprint name
+	fetch         func(startAt int) (*IssuesPage, error)
+	configuration *jira.BoardConfiguration
+	issues        []jira.Issue
+	total         int    // how many issues jira says there are
+	loading       bool   // more pages on the way
+	swimlanes     string // what the lanes are, or none if empty
+	column        int    // the focused column
+	card          int    // the focused card in that column, counting down through the lanes
+	accentColor   lipgloss.Color
+	width         int
+	height        int
+}
+
+// a board's configuration arrived for a view
+type boardConfigurationEvent struct {
+	view          int
+	configuration *jira.BoardConfiguration
+}
+
+// the user wants the issues on the board's columns instead of a table
+type OpenKanbanMsg struct {
+	BoardID int
+	Title   string
+	Fetch   func(startAt int) (*IssuesPage, error)
+}
+
+// cards in one lane, by column
+type kanbanLane struct {
+	name    string
+	columns [][]jira.Issue
+}
+
+func NewKanbanView(jiraData Backend, boardID int, title string, fetch func(startAt int) (*IssuesPage, error), accentColor lipgloss.Color, width int, height int) KanbanView {
+	return KanbanView{
+		id:          nextViewID(),
+		jiraData:    jiraData,
+		boardID:     boardID,
+		title:       title,
+		fetch:       fetch,
+		issues:      make([]jira.Issue, 0),
+		loading:     true,
+		accentColor: accentColor,
+		width:       width,
+		height:      height,
+	}
+}
+
+func (k KanbanView) Init() tea.Cmd {
+	return tea.Batch(k.fetchConfiguration(), k.fetchPage(0))
+}
+
+func (k KanbanView) fetchConfiguration() tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		configuration, err := k.jiraData.GetBoardConfiguration(k.boardID)
+		return boardConfigurationEvent{view: k.id, configuration: configuration}, err
+	})
+}
+
+func (k KanbanView) fetchPage(startAt int) tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		page, err := k.fetch(startAt)
+		return issuesPageEvent{view: k.id, page: page}, err
+	})
+}
+
+func (k KanbanView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		k.width = msg.Width
+		k.height = msg.Height
+
+	case boardConfigurationEvent:
+		if msg.view == k.id {
+			k.configuration = msg.configuration
+		}
+
+	case issuesPageEvent:
+		if msg.view != k.id {
+			break
+		}
+		k.issues = append(k.issues, msg.page.Issues...)
+		k.total = msg.page.Total
+		// keep going until we have all of them
+		k.loading = !msg.page.IsLast()
+		if k.loading {
+			return k, k.fetchPage(msg.page.StartAt + len(msg.page.Issues))
+		}
+
+	case AccentColorMsg:
+		k.accentColor = msg.Color
+
+	// the card moves to wherever its new status puts it
+	case IssueUpdatedMsg:
+		if i := slices.IndexFunc(k.issues, func(issue jira.Issue) bool { return issue.ID == msg.Issue.ID }); i >= 0 {
+			k.issues[i] = *msg.Issue
+		}
+
+	case tea.KeyMsg:
+		if action, ok := keymap.Keys.Match(msg, keymap.Issues); ok {
+			view, cmd, _ := k.Do(action)
+			return view, cmd
+		}
+	}
+
+	return k, nil
+}
+
+func (k KanbanView) Do(action string) (tea.Model, tea.Cmd, bool) {
+	if k.configuration == nil {
+		return k, nil, false
+	}
+	columns := len(k.configuration.ColumnConfig.Columns)
+	switch action {
+	case "left":
+		k.column = max(k.column-1, 0)
+	case "right":
+		k.column = min(k.column+1, max(columns-1, 0))
+	case "up":
+		k.card--
+	case "down":
+		k.card++
+	case "top":
+		k.card = 0
+	case "bottom":
+		k.card = len(k.columnCards(k.column)) - 1
+	case "new_issue":
+		boardID := k.boardID
+		return k, func() tea.Msg { return NewIssueMsg{BoardID: boardID} }, true
+	case "swimlanes":
+		next := (slices.Index(swimlaneModes, k.swimlanes) + 1) % len(swimlaneModes)
+		k.swimlanes = swimlaneModes[next]
+	case "enter":
+		if issue, ok := k.focusedIssue(); ok {
+			return k, func() tea.Msg { return OpenIssueMsg{IssueID: issue.Key} }, true
+		}
+	case "transition":
+		if issue, ok := k.focusedIssue(); ok {
+			return k, func() tea.Msg { return TransitionIssueMsg{IssueID: issue.Key} }, true
+		}
+	default:
+		return k, nil, false
+	}
+	// stay on a card, even if the column we moved to is shorter
+	k.card = min(max(k.card, 0), max(len(k.columnCards(k.column))-1, 0))
+	return k, nil, true
+}
+
+// which column each status goes in. issues in a status that isn't on the
+// board don't show up, same as in jira.
+func (k KanbanView) statusColumns() map[string]int {
+	columns := make(map[string]int)
+	for i, column := range k.configuration.ColumnConfig.Columns {
+		for _, status := range column.Status {
+			columns[status.ID] = i
+		}
+	}
+	return columns
+}
+
+// what lane an issue goes in
+func (k KanbanView) laneName(issue jira.Issue) string {
+	switch k.swimlanes {
+	case "assignee":
+		return displayName(issue.Fields.Assignee)
+	case "epic":
+		return epicName(issue)
+	}
+	return ""
+}
+
+// lanes in the order their first issue turns up, with everything that
+// doesn't fit one at the end
+func (k KanbanView) lanes() []kanbanLane {
+	statusColumns := k.statusColumns()
+	columns := len(k.configuration.ColumnConfig.Columns)
+	lanes := make([]kanbanLane, 0)
+
+	for _, issue := range k.issues {
+		if issue.Fields.Status == nil {
+			continue
+		}
+		column, ok := statusColumns[issue.Fields.Status.ID]
+		if !ok {
+			continue
+		}
+		name := k.laneName(issue)
+		i := slices.IndexFunc(lanes, func(lane kanbanLane) bool { return lane.name == name })
+		if i < 0 {
+			lanes = append(lanes, kanbanLane{name: name, columns: make([][]jira.Issue, columns)})
+			i = len(lanes) - 1
+		}
+		lanes[i].columns[column] = append(lanes[i].columns[column], issue)
+	}
+
+	slices.SortStableFunc(lanes, func(a kanbanLane, b kanbanLane) int {
+		aNone, bNone := a.name == "Unassigned" || a.name == noEpic, b.name == "Unassigned" || b.name == noEpic
+		switch {
+		case aNone && !bNone:
+			return 1
+		case !aNone && bNone:
+			return -1
+		}
+		return 0
+	})
+	return lanes
+}
+
+// every card in a column, top to bottom through the lanes
+func (k KanbanView) columnCards(column int) []jira.Issue {
+	if k.configuration == nil {
+		return nil
+	}
+	cards := make([]jira.Issue, 0)
+	for _, lane := range k.lanes() {
+		if column < len(lane.columns) {
+			cards = append(cards, lane.columns[column]...)
+		}
+	}
+	return cards
+}
+
+func (k KanbanView) focusedIssue() (jira.Issue, bool) {
+	cards := k.columnCards(k.column)
+	if k.card >= len(cards) {
+		return jira.Issue{}, false
+	}
+	return cards[k.card], true
+}
+
+func (k KanbanView) Title() string {
+	return k.title
+}
+
+func (k KanbanView) Progress() (int, int, bool) {
+	return len(k.issues), k.total, !k.loading && k.configuration != nil
+}
+
+func (k KanbanView) View() string {
+	if k.configuration == nil {
+		return "loading board..."
+	}
+	columns := k.configuration.ColumnConfig.Columns
+	if len(columns) == 0 {
+		return "this board has no columns"
+	}
+	columnWidth := max(k.width/len(columns), minKanbanColumnWidth)
+
+	// column headers stay put, the cards underneath scroll
+	headers := make([]string, 0, len(columns))
+	for i, column := range columns {
+		headers = append(headers, k.renderColumnHeader(column, len(k.columnCards(i)), columnWidth))
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Top, headers...)
+
+	// lay out every lane, keeping track of where the focused card ends so it
+	// can be scrolled into view
+	blocks := make([]string, 0)
+	lines, focusBottom, seen := 0, 0, 0
+	for _, lane := range k.lanes() {
+		if k.swimlanes != "" {
+			blocks = append(blocks, laneHeaderStyle.Render(lane.name))
+			lines++
+		}
+		rendered := make([]string, 0, len(lane.columns))
+		for i, cards := range lane.columns {
+			column := make([]string, 0, len(cards))
+			height := 0
+			for j, card := range cards {
+				focused := i == k.column && seen+j == k.card
+				c := k.renderCard(card, focused, columnWidth)
+				height += lipgloss.Height(c)
+				if focused {
+					focusBottom = lines + height
+				}
+				column = append(column, c)
+			}
+			if i == k.column {
+				seen += len(cards)
+			}
+			rendered = append(rendered, lipgloss.NewStyle().Width(columnWidth).Render(lipgloss.JoinVertical(lipgloss.Left, column...)))
+		}
+		block := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+		blocks = append(blocks, block)
+		lines += lipgloss.Height(block)
+	}
+
+	body := strings.Split(lipgloss.JoinVertical(lipgloss.Left, blocks...), "\n")
+	bodyHeight := max(k.height-lipgloss.Height(header), 1)
+	top := min(max(focusBottom-bodyHeight, 0), max(len(body)-1, 0))
+	body = body[top:min(top+bodyHeight, len(body))]
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, strings.Join(body, "\n"))
+}
+
+// the column's name and card count, in red if it's over or under its limits
+func (k KanbanView) renderColumnHeader(column jira.BoardConfigurationColumn, count int, width int) string {
+	text := fmt.Sprintf("%s %d", column.Name, count)
+	limits := make([]string, 0, 2)
+	if column.Min > 0 {
+		limits = append(limits, fmt.Sprintf("min %d", column.Min))
+	}
+	if column.Max > 0 {
+		limits = append(limits, fmt.Sprintf("max %d", column.Max))
+	}
+	if len(limits) > 0 {
+		text = fmt.Sprintf("%s (%s)", text, strings.Join(limits, ", "))
+	}
+
+	style := columnHeaderStyle
+	if (column.Max > 0 && count > column.Max) || (column.Min > 0 && count < column.Min) {
+		style = wipExceededStyle
+	}
+	// cut short rather than wrap, so every header is one line
+	return lipgloss.NewStyle().Width(width).Render(style.Inline(true).MaxWidth(max(width-1, 1)).Render(text))
+}
+
+func (k KanbanView) renderCard(issue jira.Issue, focused bool, width int) string {
+	style := lightBorder
+	if focused {
+		style = style.BorderForeground(k.accentColor)
+	}
+	return style.Width(max(width-2, 1)).Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Render(issue.Key),
+		issue.Fields.Summary,
+		cardDetailStyle.Render(displayName(issue.Fields.Assignee)),
+	))
+}
+
+const noEpic = "No epic"
+
+// classic projects have an epic, team-managed ones have a parent
+func epicName(issue jira.Issue) string {
+	switch {
+	case issue.Fields.Epic != nil && issue.Fields.Epic.Name != "":
+		return issue.Fields.Epic.Name
+	case issue.Fields.Epic != nil:
+		return issue.Fields.Epic.Key
+	case issue.Fields.Parent != nil:
+		return issue.Fields.Parent.Key
+	}
+	return noEpic
+}
@@ -0,0 +1,280 @@
+package jira
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// how a worklog's start is typed in, in local time
+const worklogStartedLayout = "2006-01-02 15:04"
+
+// the dialog's fields, in the order they're shown
+const (
+	worklogTimeSpent = iota
+	worklogStarted
+	worklogComment
+	worklogRemaining
+	worklogNewEstimate // only shown when the remaining estimate is set
+)
+
+// jira's names for the dialog's fields, going by the errors it sends back
+var worklogErrorFields = map[string]int{
+	"timeLogged":  worklogTimeSpent,
+	"startDate":   worklogStarted,
+	"comment":     worklogComment,
+	"newEstimate": worklogNewEstimate,
+}
+
+// logs time against an issue: how long, since when, what for, and what
+// happens to the remaining estimate
+type LogWorkDialog struct {
+	jiraData    Backend
+	issueID     string
+	fields      []formField
+	focus       int
+	err         string // what jira didn't like that isn't about one field
+	busy        bool   // waiting on jira
+	open        bool
+	accentColor lipgloss.Color
+	width       int
+}
+
+// the user wants to log time against an issue
+type LogWorkMsg struct {
+	IssueID string
+}
+
+// the work was logged, here's the issue with its time tracking caught up
+type workLoggedEvent struct {
+	issue *jira.Issue
+}
+
+// jira wouldn't log the work, and said why
+type logWorkRejectedEvent struct {
+	err error
+}
+
+func NewLogWorkDialog(jiraData Backend, accentColor lipgloss.Color) LogWorkDialog {
+	return LogWorkDialog{jiraData: jiraData, accentColor: accentColor}
+}
+
+// start over for another issue, starting now
+func (d LogWorkDialog) Open(issueID string) (LogWorkDialog, tea.Cmd) {
+	d = LogWorkDialog{jiraData: d.jiraData, accentColor: d.accentColor, width: d.width, issueID: issueID, open: true}
+	text := func(name string, placeholder string, required bool) formField {
+		field, _ := newFormField("", FieldMeta{Required: required, Name: name, Schema: FieldSchema{Type: "string"}})
+		field.input.Placeholder = placeholder
+		return field
+	}
+	remaining := newSelectField("", FieldMeta{Required: true, Name: "Remaining", AllowedValues: []AllowedValue{
+		{ID: estimateAuto, Name: "reduce by time spent"},
+		{ID: estimateLeave, Name: "leave as is"},
+		{ID: estimateNew, Name: "set to"},
+	}})
+	remaining.choice = 0
+	d.fields = []formField{
+		text("Time spent", "e.g. 1h 30m", true),
+		text("Started", "YYYY-MM-DD HH:MM", true),
+		text("Comment", "", false),
+		remaining,
+		text("New estimate", "e.g. 2d", true),
+	}
+	d.fields[worklogStarted].input.SetValue(time.Now().Format(worklogStartedLayout))
+	d.resizeInputs()
+	return d, d.focusField(worklogTimeSpent)
+}
+
+func (d LogWorkDialog) Focused() bool {
+	return d.open
+}
+
+func (d LogWorkDialog) logWork(worklog jira.WorklogRecord, estimate RemainingEstimate) tea.Cmd {
+	issueID := d.issueID
+	return change(func() (tea.Msg, error) {
+		err := d.jiraData.AddWorklog(issueID, worklog, estimate)
+		if reason, ok := rejected(err); ok {
+			return logWorkRejectedEvent{err: reason}, nil
+		}
+		return nil, err
+	}, request(func() (tea.Msg, error) {
+		issue, err := d.jiraData.GetIssue(issueID)
+		return workLoggedEvent{issue: issue}, err
+	}))
+}
+
+func (d LogWorkDialog) Update(msg tea.Msg) (LogWorkDialog, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.resizeInputs()
+
+	case AccentColorMsg:
+		d.accentColor = msg.Color
+
+	case workLoggedEvent:
+		d.open = false
+		return d, func() tea.Msg { return IssueUpdatedMsg{Issue: msg.issue} }
+
+	case logWorkRejectedEvent:
+		d.busy = false
+		d.showErrors(msg.err)
+
+	case tea.KeyMsg:
+		if !d.open || d.busy {
+			if key.Matches(msg, keymap.Keys.Back) {
+				d.open = false
+			}
+			break
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			d.open = false
+			return d, nil
+		case key.Matches(msg, keymap.Keys.Submit):
+			return d.submit()
+		case key.Matches(msg, keymap.Keys.NextField), msg.Type == tea.KeyDown:
+			return d, d.step(1)
+		case key.Matches(msg, keymap.Keys.PrevField), msg.Type == tea.KeyUp:
+			return d, d.step(-1)
+		// enter moves along, and logs the work from the last field
+		case key.Matches(msg, keymap.Keys.Enter):
+			shown := d.shown()
+			if d.focus == shown[len(shown)-1] {
+				return d.submit()
+			}
+			return d, d.step(1)
+		}
+		field := &d.fields[d.focus]
+		cmd := field.update(msg)
+		field.err = ""
+		return d, cmd
+	}
+
+	return d, nil
+}
+
+// the fields showing: the new estimate only matters when it's being set
+func (d LogWorkDialog) shown() []int {
+	shown := []int{worklogTimeSpent, worklogStarted, worklogComment, worklogRemaining}
+	if d.estimate() == estimateNew {
+		shown = append(shown, worklogNewEstimate)
+	}
+	return shown
+}
+
+// what's picked for the remaining estimate
+func (d LogWorkDialog) estimate() string {
+	remaining := d.fields[worklogRemaining]
+	return remaining.meta.AllowedValues[remaining.choice].ID
+}
+
+// move the focus along the fields showing, wrapping around
+func (d *LogWorkDialog) step(by int) tea.Cmd {
+	shown := d.shown()
+	at := max(slices.Index(shown, d.focus), 0)
+	return d.focusField(shown[(at+by+len(shown))%len(shown)])
+}
+
+func (d *LogWorkDialog) focusField(focus int) tea.Cmd {
+	d.focus = focus
+	var cmd tea.Cmd
+	for i := range d.fields {
+		if i == d.focus {
+			cmd = d.fields[i].focus()
+		} else {
+			d.fields[i].blur()
+		}
+	}
+	return cmd
+}
+
+func (d *LogWorkDialog) resizeInputs() {
+	for i := range d.fields {
+		d.fields[i].input.Width = max(d.width-d.labelWidth()-4, 1)
+	}
+}
+
+// check what was typed in makes sense, and send it off if it does
+func (d LogWorkDialog) submit() (LogWorkDialog, tea.Cmd) {
+	for i := range d.fields {
+		d.fields[i].err = ""
+	}
+	spent := strings.TrimSpace(d.fields[worklogTimeSpent].input.Value())
+	if seconds, err := parseWorklogDuration(spent); err != nil || seconds <= 0 {
+		d.fields[worklogTimeSpent].err = "durations look like 1h 30m"
+	}
+	started, err := time.ParseInLocation(worklogStartedLayout, strings.TrimSpace(d.fields[worklogStarted].input.Value()), time.Local)
+	if err != nil {
+		d.fields[worklogStarted].err = "starts look like 2024-01-31 09:30"
+	}
+	estimate := RemainingEstimate{Adjust: d.estimate()}
+	if estimate.Adjust == estimateNew {
+		estimate.New = strings.TrimSpace(d.fields[worklogNewEstimate].input.Value())
+		if _, err := parseWorklogDuration(estimate.New); err != nil {
+			d.fields[worklogNewEstimate].err = "estimates look like 2d 4h"
+		}
+	}
+	if i := slices.IndexFunc(d.fields, func(field formField) bool { return field.err != "" }); i >= 0 {
+		return d, d.focusField(i)
+	}
+
+	jiraStarted := jira.Time(started)
+	worklog := jira.WorklogRecord{
+		TimeSpent: spent,
+		Started:   &jiraStarted,
+		Comment:   strings.TrimSpace(d.fields[worklogComment].input.Value()),
+	}
+	d.busy = true
+	d.err = ""
+	return d, d.logWork(worklog, estimate)
+}
+
+// put each of jira's complaints next to its field, and anything else
+// underneath
+func (d *LogWorkDialog) showErrors(err error) {
+	problems, others := fieldErrors(err)
+	for name, problem := range problems {
+		if i, ok := worklogErrorFields[name]; ok {
+			d.fields[i].err = problem
+		} else {
+			others = append(others, problem)
+		}
+	}
+	slices.Sort(others)
+	d.err = strings.Join(others, "\n")
+	if i := slices.IndexFunc(d.fields, func(field formField) bool { return field.err != "" }); i >= 0 {
+		d.focusField(i)
+	}
+}
+
+// room for the longest label and the required marker
+func (d LogWorkDialog) labelWidth() int {
+	width := 0
+	for _, field := range d.fields {
+		width = max(width, lipgloss.Width(field.meta.Name)+1)
+	}
+	return width
+}
+
+func (d LogWorkDialog) View() string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("log work on " + d.issueID)}
+	for _, i := range d.shown() {
+		lines = append(lines, d.fields[i].view(d.labelWidth(), d.accentColor, d.width))
+	}
+	switch {
+	case d.busy:
+		lines = append(lines, jqlHintStyle.Render("logging work..."))
+	case d.err != "":
+		lines = append(lines, jqlErrorStyle.Width(d.width).Render(d.err))
+	}
+	lines = append(lines, jqlHintStyle.Render(strings.Join([]string{keymap.Does("next/log", keymap.Keys.Enter), keymap.Does("move", keymap.Keys.NextField, keymap.Keys.PrevField), "←/→: pick", keymap.Does("cancel", keymap.Keys.Back)}, " • ")))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,251 @@
+package jira
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// where atlassian's oauth 2.0 (3LO) apps sign in
+var atlassianEndpoint = oauth2.Endpoint{
+	AuthURL:  "https://auth.atlassian.com/authorize",
+	TokenURL: "https://auth.atlassian.com/oauth/token",
+}
+
+// what the app needs to read and change issues, and to stay signed in
+var defaultOAuthScopes = []string{"read:jira-work", "write:jira-work", "read:jira-user", "offline_access"}
+
+// how long to wait for the user to approve a login in their browser
+const approveTimeout = 5 * time.Minute
+
+// cloud sites are reached through the api gateway, by their cloud id
+const (
+	accessibleResourcesURL = "https://api.atlassian.com/oauth/token/accessible-resources"
+	apiGatewayURL          = "https://api.atlassian.com/ex/jira/"
+)
+
+func oauthConfig(o config.OAuth2) *oauth2.Config {
+	scopes := o.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOAuthScopes
+	}
+	return &oauth2.Config{
+		ClientID:     o.ClientID,
+		ClientSecret: o.ClientSecret,
+		Endpoint:     atlassianEndpoint,
+		RedirectURL:  fmt.Sprintf("http://localhost:%d/callback", o.RedirectPort),
+		Scopes:       scopes,
+	}
+}
+
+// sign in with the tokens saved last time, refreshing them as they expire
+// and saving them again. with nothing saved, or if the refresh token's
+// been revoked, the user's asked to approve a new login.
+func oauthClient(profile config.Profile, approve Approver) (*http.Client, string, error) {
+	ctx := context.Background()
+	conf := oauthConfig(profile.Auth.OAuth2)
+	path := profile.Auth.OAuth2.TokenFile
+
+	token, err := readToken(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var source oauth2.TokenSource
+	if token != nil {
+		source = newSavingTokenSource(conf.TokenSource(ctx, token), path, token)
+		if _, err := source.Token(); err != nil {
+			slog.Info("saved oauth token doesn't work, logging in again", "profile", profile.Name, "err", err)
+			token = nil
+		}
+	}
+	if token == nil {
+		if approve == nil {
+			return nil, "", fmt.Errorf("profile %q isn't logged in; start with --profile %s to approve it in a browser", profile.Name, profile.Name)
+		}
+		if token, err = authorize(ctx, conf, approve); err != nil {
+			return nil, "", err
+		}
+		if err := writeToken(path, token); err != nil {
+			return nil, "", err
+		}
+		source = newSavingTokenSource(conf.TokenSource(ctx, token), path, token)
+	}
+
+	client := oauth2.NewClient(ctx, source)
+	cloudID, err := findCloudID(client, profile.Url)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, apiGatewayURL + cloudID + "/", nil
+}
+
+// send the user off to approve the app, and wait for atlassian to send
+// them back to a listener here with a code to trade for tokens
+func authorize(ctx context.Context, conf *oauth2.Config, approve Approver) (*oauth2.Token, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	state := hex.EncodeToString(nonce)
+
+	redirect := strings.TrimPrefix(conf.RedirectURL, "http://")
+	listener, err := net.Listen("tcp", strings.TrimSuffix(redirect, "/callback"))
+	if err != nil {
+		return nil, fmt.Errorf("listening for the oauth callback: %w", err)
+	}
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		res := result{code: query.Get("code")}
+		switch {
+		case query.Get("state") != state:
+			res.err = errors.New("the oauth callback's state doesn't match, so it's not from this login")
+		case query.Get("error") != "":
+			res.err = fmt.Errorf("the login wasn't approved: %s", query.Get("error_description"))
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Logged in. You can close this tab and go back to the terminal.")
+		}
+		// only the first callback counts
+		select {
+		case results <- res:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	approve(conf.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("audience", "api.atlassian.com"),
+		oauth2.SetAuthURLParam("prompt", "consent"),
+	))
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		token, err := conf.Exchange(ctx, res.code)
+		if err != nil {
+			return nil, fmt.Errorf("trading the oauth code for a token: %w", err)
+		}
+		return token, nil
+	case <-time.After(approveTimeout):
+		return nil, errors.New("gave up waiting for the login to be approved")
+	}
+}
+
+// the id the api gateway knows the site at url by, out of the sites the
+// app's been let into
+func findCloudID(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(accessibleResourcesURL)
+	if err != nil {
+		return "", fmt.Errorf("finding %s's cloud id: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("finding %s's cloud id: %s", url, resp.Status)
+	}
+	sites := make([]struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}, 0)
+	if err := json.NewDecoder(resp.Body).Decode(&sites); err != nil {
+		return "", fmt.Errorf("finding %s's cloud id: %w", url, err)
+	}
+	urls := make([]string, 0, len(sites))
+	for _, site := range sites {
+		if strings.TrimSuffix(site.URL, "/") == strings.TrimSuffix(url, "/") {
+			return site.ID, nil
+		}
+		urls = append(urls, site.URL)
+	}
+	slices.Sort(urls)
+	return "", fmt.Errorf("the oauth app hasn't been let into %s, only %s", url, strings.Join(urls, ", "))
+}
+
+// hands out tokens from source, saving each new one, since atlassian
+// rotates the refresh token every time it's used
+type savingTokenSource struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+	path   string
+	saved  string // the access token last saved
+}
+
+func newSavingTokenSource(source oauth2.TokenSource, path string, saved *oauth2.Token) *savingTokenSource {
+	return &savingTokenSource{source: source, path: path, saved: saved.AccessToken}
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.saved {
+		// still good for this run, so not worth failing over
+		if err := writeToken(s.path, token); err != nil {
+			slog.Error("couldn't save the refreshed oauth token", "err", err)
+		}
+		s.saved = token.AccessToken
+	}
+	return token, nil
+}
+
+// the token saved last time, or nil if there isn't one
+func readToken(path string) (*oauth2.Token, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the saved oauth token: %w", err)
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(raw, token); err != nil {
+		return nil, fmt.Errorf("reading the saved oauth token %s: %w", path, err)
+	}
+	return token, nil
+}
+
+// only the user gets to read it, since it's as good as a password
+func writeToken(path string, token *oauth2.Token) error {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("saving the oauth token: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		return fmt.Errorf("saving the oauth token: %w", err)
+	}
+	return nil
+}
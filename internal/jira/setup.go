@@ -0,0 +1,366 @@
+package jira
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// the wizard's fields, in the order they're shown
+const (
+	setupURL = iota
+	setupAuth
+	setupEmail
+	setupAPIToken
+	setupAccessToken
+	setupUsername
+	setupPassword
+	setupClientID
+	setupClientSecret
+	setupTokenSource
+)
+
+// what each way of signing in asks for, after the url and the way itself
+var setupAuthFields = map[string][]int{
+	config.AuthBasic:   {setupEmail, setupAPIToken, setupTokenSource},
+	config.AuthBearer:  {setupAccessToken, setupTokenSource},
+	config.AuthSession: {setupUsername, setupPassword},
+	config.AuthOAuth2:  {setupClientID, setupClientSecret},
+}
+
+// the first time the app's run, asks what jira to talk to and how to sign
+// in, checks that works, and writes it to a config file
+type SetupWizard struct {
+	path        string   // where the config's written
+	approve     Approver // opens oauth logins in a browser
+	fields      []formField
+	focus       int
+	err         string     // why it couldn't sign in, or write the config
+	busy        bool       // signing in
+	authURL     string     // the oauth login waiting to be approved
+	self        *jira.User // who it signed in as, once the config's written
+	accentColor lipgloss.Color
+	width       int
+}
+
+// signed in with what was typed in, and wrote the config
+type setupDoneEvent struct {
+	self *jira.User
+}
+
+// couldn't sign in, or couldn't write the config
+type setupFailedEvent struct {
+	err error
+}
+
+// an oauth login is waiting to be approved at url. more could come on urls.
+type setupApproveEvent struct {
+	url  string
+	urls chan string
+}
+
+func NewSetupWizard(path string, approve Approver, accentColor lipgloss.Color) SetupWizard {
+	text := func(name string, placeholder string, secret bool) formField {
+		field, _ := newFormField("", FieldMeta{Required: true, Name: name, Schema: FieldSchema{Type: "string"}})
+		field.input.Placeholder = placeholder
+		if secret {
+			field.input.EchoMode = textinput.EchoPassword
+			field.input.EchoCharacter = '•'
+		}
+		return field
+	}
+	auth := newSelectField("", FieldMeta{Required: true, Name: "Sign in with", AllowedValues: []AllowedValue{
+		{ID: config.AuthBasic, Name: "email and api token (cloud)"},
+		{ID: config.AuthBearer, Name: "personal access token (server, data center)"},
+		{ID: config.AuthSession, Name: "username and password (server, data center)"},
+		{ID: config.AuthOAuth2, Name: "an oauth 2.0 app (cloud)"},
+	}})
+	auth.choice = 0
+	tokenSource := newSelectField("", FieldMeta{Required: true, Name: "Keep token in", AllowedValues: []AllowedValue{
+		{ID: config.TokenSourceKeyring, Name: "the os keyring"},
+		{ID: config.TokenSourceConfig, Name: "the config file, in plaintext"},
+	}})
+	tokenSource.choice = 0
+	d := SetupWizard{path: path, approve: approve, accentColor: accentColor}
+	d.fields = []formField{
+		text("URL", "https://your-site.atlassian.net, or fake:// to try it offline", false),
+		auth,
+		text("Email", "", false),
+		text("API token", "", true),
+		text("Access token", "", true),
+		text("Username", "", false),
+		text("Password", "", true),
+		text("Client ID", "", false),
+		text("Client secret", "", true),
+		tokenSource,
+	}
+	d.focusField(setupURL)
+	return d
+}
+
+// who the config signs in as, or nil if it wasn't written
+func (d SetupWizard) Self() *jira.User {
+	return d.self
+}
+
+func (d SetupWizard) Init() tea.Cmd {
+	return nil
+}
+
+// sign in off the main loop, and write the config if that works, with the
+// token kept where tokenSource says
+func (d SetupWizard) signIn(profile config.Profile, tokenSource string) tea.Cmd {
+	urls := make(chan string, 1)
+	approve := func(authURL string) {
+		urls <- authURL
+		if d.approve != nil {
+			d.approve(authURL)
+		}
+	}
+	path := d.path
+	signIn := func() tea.Msg {
+		defer close(urls)
+		jiraData, err := NewBackend(profile, approve)
+		if err != nil {
+			return setupFailedEvent{err: err}
+		}
+		if tokenSource == config.TokenSourceKeyring {
+			profile.TokenSource = tokenSource
+			if err := profile.SaveToken(profile.Token); err != nil {
+				return setupFailedEvent{err: fmt.Errorf("%w; keep it in the config file instead", err)}
+			}
+			profile.Token = ""
+		}
+		if err := config.Write(path, profile); err != nil {
+			return setupFailedEvent{err: err}
+		}
+		return setupDoneEvent{self: jiraData.Self()}
+	}
+	return tea.Batch(signIn, waitForApproval(urls))
+}
+
+// wait for an oauth login to need approving, which only happens to oauth
+// profiles; the rest are done with once urls is closed
+func waitForApproval(urls chan string) tea.Cmd {
+	return func() tea.Msg {
+		authURL, ok := <-urls
+		if !ok {
+			return nil
+		}
+		return setupApproveEvent{url: authURL, urls: urls}
+	}
+}
+
+func (d SetupWizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.resizeInputs()
+
+	case setupApproveEvent:
+		d.authURL = msg.url
+		return d, waitForApproval(msg.urls)
+
+	case setupDoneEvent:
+		d.self = msg.self
+		return d, tea.Quit
+
+	case setupFailedEvent:
+		d.busy, d.authURL = false, ""
+		d.err = msg.err.Error()
+
+	case tea.KeyMsg:
+		// q is for typing, so only ctrl+c quits
+		if msg.Type == tea.KeyCtrlC || key.Matches(msg, keymap.Keys.Back) {
+			return d, tea.Quit
+		}
+		if d.busy {
+			break
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Submit):
+			return d.submit()
+		case key.Matches(msg, keymap.Keys.NextField), msg.Type == tea.KeyDown:
+			return d, d.step(1)
+		case key.Matches(msg, keymap.Keys.PrevField), msg.Type == tea.KeyUp:
+			return d, d.step(-1)
+		// enter moves along, and signs in from the last field
+		case key.Matches(msg, keymap.Keys.Enter):
+			shown := d.shown()
+			if d.focus == shown[len(shown)-1] {
+				return d.submit()
+			}
+			return d, d.step(1)
+		}
+		field := &d.fields[d.focus]
+		cmd := field.update(msg)
+		field.err = ""
+		return d, cmd
+	}
+
+	return d, nil
+}
+
+// the fields showing: the url, the way of signing in, and what it asks for.
+// the fake backend doesn't sign in, so it only needs the url.
+func (d SetupWizard) shown() []int {
+	if d.offline() {
+		return []int{setupURL}
+	}
+	return append([]int{setupURL, setupAuth}, setupAuthFields[d.auth()]...)
+}
+
+// whether the url's for the fake backend
+func (d SetupWizard) offline() bool {
+	u, err := url.Parse(d.value(setupURL))
+	return err == nil && u.Scheme == FakeScheme
+}
+
+// the way of signing in that's picked
+func (d SetupWizard) auth() string {
+	auth := d.fields[setupAuth]
+	return auth.meta.AllowedValues[auth.choice].ID
+}
+
+func (d SetupWizard) value(field int) string {
+	return strings.TrimSpace(d.fields[field].input.Value())
+}
+
+// move the focus along the fields showing, wrapping around
+func (d *SetupWizard) step(by int) tea.Cmd {
+	shown := d.shown()
+	at := max(slices.Index(shown, d.focus), 0)
+	return d.focusField(shown[(at+by+len(shown))%len(shown)])
+}
+
+func (d *SetupWizard) focusField(focus int) tea.Cmd {
+	d.focus = focus
+	var cmd tea.Cmd
+	for i := range d.fields {
+		if i == d.focus {
+			cmd = d.fields[i].focus()
+		} else {
+			d.fields[i].blur()
+		}
+	}
+	return cmd
+}
+
+func (d *SetupWizard) resizeInputs() {
+	for i := range d.fields {
+		d.fields[i].input.Width = max(d.width-d.labelWidth()-4, 1)
+	}
+}
+
+// check everything's filled in, and sign in with it if it is
+func (d SetupWizard) submit() (SetupWizard, tea.Cmd) {
+	d.err = ""
+	for i := range d.fields {
+		d.fields[i].err = ""
+	}
+	shown := d.shown()
+	for _, i := range shown {
+		if d.fields[i].kind == textField && d.value(i) == "" {
+			d.fields[i].err = "needs filling in"
+		}
+	}
+	u, err := url.Parse(d.value(setupURL))
+	if d.value(setupURL) != "" && (err != nil || u.Host == "" && u.Scheme != FakeScheme) {
+		d.fields[setupURL].err = "urls look like https://your-site.atlassian.net"
+	}
+	if i := slices.IndexFunc(d.fields, func(field formField) bool { return field.err != "" }); i >= 0 {
+		return d, d.focusField(i)
+	}
+
+	profile := config.Profile{Name: config.DefaultProfileName, Url: d.value(setupURL)}
+	if u.Host != "" {
+		profile.Url = strings.TrimSuffix(profile.Url, "/")
+	}
+	if !d.offline() {
+		profile.Auth.Type = d.auth()
+	}
+	switch profile.Auth.Type {
+	case config.AuthBasic:
+		profile.Email, profile.Token = d.value(setupEmail), d.value(setupAPIToken)
+	case config.AuthBearer:
+		profile.Token = d.value(setupAccessToken)
+	case config.AuthSession:
+		profile.Auth.Username, profile.Auth.Password = d.value(setupUsername), d.value(setupPassword)
+	case config.AuthOAuth2:
+		profile.Auth.OAuth2.ClientID, profile.Auth.OAuth2.ClientSecret = d.value(setupClientID), d.value(setupClientSecret)
+	}
+	profile = profile.WithDefaults()
+	if err := profile.Validate(); err != nil {
+		d.err = err.Error()
+		return d, nil
+	}
+	// it signs in with the token as typed, and keeps it once that works
+	tokenSource := config.TokenSourceConfig
+	if slices.Contains(shown, setupTokenSource) {
+		field := d.fields[setupTokenSource]
+		tokenSource = field.meta.AllowedValues[field.choice].ID
+	}
+	d.busy = true
+	return d, d.signIn(profile, tokenSource)
+}
+
+// room for the longest label and the required marker
+func (d SetupWizard) labelWidth() int {
+	width := 0
+	for _, field := range d.fields {
+		width = max(width, lipgloss.Width(field.meta.Name)+1)
+	}
+	return width
+}
+
+// where to get what the way of signing in asks for
+func (d SetupWizard) authHint() string {
+	if d.offline() {
+		return "fake:// shows sample issues without signing in"
+	}
+	switch d.auth() {
+	case config.AuthBasic:
+		return "make an api token at https://id.atlassian.com/manage-profile/security/api-tokens"
+	case config.AuthBearer:
+		return "make a personal access token from your profile in jira"
+	case config.AuthOAuth2:
+		return fmt.Sprintf("make an oauth 2.0 (3LO) app at https://developer.atlassian.com/console/myapps with http://localhost:%d/callback as its callback url", config.DefaultRedirectPort)
+	}
+	return ""
+}
+
+func (d SetupWizard) View() string {
+	hint := jqlHintStyle.Width(d.width)
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render("set up go-jira-tui"),
+		hint.Render("there's no config yet, so let's make one. it'll be written to " + d.path + "."),
+		"",
+	}
+	for _, i := range d.shown() {
+		lines = append(lines, d.fields[i].view(d.labelWidth(), d.accentColor, d.width))
+	}
+	lines = append(lines, "")
+	if authHint := d.authHint(); authHint != "" {
+		lines = append(lines, hint.Render(authHint))
+	}
+	switch {
+	case d.authURL != "":
+		lines = append(lines, "approve the login in your browser. if it didn't open, go to:", d.authURL)
+	case d.busy:
+		lines = append(lines, jqlHintStyle.Render("signing in..."))
+	case d.err != "":
+		lines = append(lines, jqlErrorStyle.Width(d.width).Render(d.err))
+	}
+	lines = append(lines, jqlHintStyle.Render(strings.Join([]string{keymap.Does("next/sign in", keymap.Keys.Enter), keymap.Does("move", keymap.Keys.NextField, keymap.Keys.PrevField), "←/→: pick", keymap.Does("quit", keymap.Keys.Back)}, " • ")))
+	return strings.Join(lines, "\n")
+}
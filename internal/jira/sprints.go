@@ -0,0 +1,224 @@
+package jira
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/evertras/bubble-table/table"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+const (
+	columnKeyState     = "state"      // sprint state
+	columnKeyStartDate = "start_date" // sprint start date
+	columnKeyEndDate   = "end_date"   // sprint end date
+)
+
+// sprint states, in the order they're listed and cycled through. the empty
+// state shows all of them.
+var sprintStates = []string{"", "active", "future", "closed"}
+
+type SprintsView struct {
+	id       int
+	jiraData Backend
+	board    jira.Board
+	sprints  []jira.Sprint
+	state    string // only show sprints in this state, or all if empty
+	total    int    // how many sprints jira says there are
+	loading  bool   // more pages on the way
+	width    int
+	table    table.Model
+}
+
+// a page of sprints arrived for a view
+type sprintsPageEvent struct {
+	view int
+	page *jira.SprintsList
+}
+
+// the user picked a sprint
+type OpenSprintMsg struct {
+	Sprint jira.Sprint
+}
+
+// the user wants the issues that aren't in a sprint yet
+type OpenBacklogMsg struct {
+	Board jira.Board
+}
+
+func NewSprintsView(jiraData Backend, board jira.Board, width int) SprintsView {
+	columns := []table.Column{
+		table.NewColumn(columnKeyID, "ID", 5),
+		table.NewFlexColumn(columnKeyName, "Name", 1),
+		table.NewColumn(columnKeyState, "State", 8),
+		table.NewColumn(columnKeyStartDate, "Start Date", 30),
+		table.NewColumn(columnKeyEndDate, "End Date", 30),
+	}
+
+	return SprintsView{
+		id:       nextViewID(),
+		jiraData: jiraData,
+		board:    board,
+		sprints:  make([]jira.Sprint, 0),
+		loading:  true,
+		width:    width,
+		table:    table.New(columns).Filtered(true).Focused(true).WithKeyMap(tableKeyMap()).WithTargetWidth(width),
+	}
+}
+
+func (s SprintsView) Init() tea.Cmd {
+	return s.fetchPage(0)
+}
+
+func (s SprintsView) fetchPage(startAt int) tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		sprints, err := s.jiraData.GetSprintsForBoard(s.board.ID, startAt)
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("retrieving sprints", "board", s.board.ID, "startAt", startAt, "sprints", sprints.Values)
+		return sprintsPageEvent{view: s.id, page: sprints}, nil
+	})
+}
+
+func (s SprintsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.table = s.table.WithTargetWidth(msg.Width).WithPageSize(tablePageSize(msg.Height))
+
+	case KeysChangedMsg:
+		s.table = s.table.WithKeyMap(tableKeyMap())
+
+	case sprintsPageEvent:
+		if msg.view != s.id {
+			break
+		}
+		s.sprints = append(s.sprints, msg.page.Values...)
+		s.total = msg.page.Total
+		s.table = s.table.WithRows(s.rows())
+
+		// keep going until jira says we're done
+		s.loading = !msg.page.IsLast && len(msg.page.Values) > 0
+		if s.loading {
+			cmds = append(cmds, s.fetchPage(msg.page.StartAt+len(msg.page.Values)))
+		}
+
+	case tea.KeyMsg:
+		if s.table.GetIsFilterInputFocused() {
+			break
+		}
+		if action, ok := keymap.Keys.Match(msg, keymap.Sprints); ok {
+			if view, cmd, done := s.Do(action); done {
+				return view, cmd
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table, cmd = updateTable(s.table, msg)
+	cmds = append(cmds, cmd)
+	return s, tea.Batch(cmds...)
+}
+
+// active sprints first, then future, then closed; newest first within each
+func (s SprintsView) rows() []table.Row {
+	sprints := slices.DeleteFunc(slices.Clone(s.sprints), func(sprint jira.Sprint) bool {
+		return s.state != "" && sprint.State != s.state
+	})
+	slices.SortStableFunc(sprints, func(a jira.Sprint, b jira.Sprint) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(sprintStates, a.State), slices.Index(sprintStates, b.State)),
+			cmp.Compare(b.ID, a.ID),
+		)
+	})
+
+	rows := make([]table.Row, 0)
+	for _, sprint := range sprints {
+		rows = append(rows, sprintToTableRow(sprint))
+	}
+	return rows
+}
+
+func (s SprintsView) highlightedSprint() (jira.Sprint, bool) {
+	row := s.table.HighlightedRow()
+	if row.Data == nil {
+		return jira.Sprint{}, false
+	}
+	for _, sprint := range s.sprints {
+		if sprint.ID == row.Data[columnKeyID] {
+			return sprint, true
+		}
+	}
+	return jira.Sprint{}, false
+}
+
+// the board's name, and which sprints we're showing
+func (s SprintsView) Title() string {
+	if s.state == "" {
+		return s.board.Name
+	}
+	return s.board.Name + " (" + s.state + ")"
+}
+
+func (s SprintsView) Progress() (int, int, bool) {
+	return len(s.sprints), s.total, !s.loading
+}
+
+func (s SprintsView) View() string {
+	return s.table.View()
+}
+
+func sprintToTableRow(sprint jira.Sprint) table.Row {
+	return table.NewRow(table.RowData{
+		columnKeyID:        sprint.ID,
+		columnKeyName:      sprint.Name,
+		columnKeyState:     sprint.State,
+		columnKeyStartDate: formatSprintDate(sprint.StartDate),
+		columnKeyEndDate:   formatSprintDate(sprint.EndDate),
+	})
+}
+
+// future sprints don't have dates yet
+func formatSprintDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(time.RFC1123)
+}
+
+func (s SprintsView) Do(action string) (tea.Model, tea.Cmd, bool) {
+	switch action {
+	case "enter":
+		sprint, ok := s.highlightedSprint()
+		if !ok {
+			return s, nil, true
+		}
+		return s, func() tea.Msg { return OpenSprintMsg{Sprint: sprint} }, true
+	case "backlog":
+		board := s.board
+		return s, func() tea.Msg { return OpenBacklogMsg{Board: board} }, true
+	case "new_issue":
+		boardID := s.board.ID
+		return s, func() tea.Msg { return NewIssueMsg{BoardID: boardID} }, true
+	case "sprint_state":
+		next := (slices.Index(sprintStates, s.state) + 1) % len(sprintStates)
+		s.state = sprintStates[next]
+		s.table = s.table.WithRows(s.rows()).WithHighlightedRow(0)
+		return s, nil, true
+	}
+	var done bool
+	s.table, done = doTable(s.table, action)
+	return s, nil, done
+}
+
+func (s SprintsView) Capturing() bool {
+	return s.table.GetIsFilterInputFocused()
+}
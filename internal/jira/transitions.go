@@ -0,0 +1,241 @@
+package jira
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+var pickerCursorStyle = lipgloss.NewStyle().Bold(true)
+
+// picks a transition for an issue, then a value for each field the
+// transition requires (e.g. a resolution when closing it)
+type TransitionPicker struct {
+	jiraData    Backend
+	issueID     string
+	transitions []Transition      // what the issue can do, nil until jira says
+	transition  *Transition       // the one picked, while its fields are filled in
+	fields      []string          // required fields left to fill in
+	values      map[string]string // field id -> allowed value id
+	cursor      int
+	err         error // why jira turned the transition down
+	busy        bool  // waiting on jira
+	open        bool
+	width       int
+}
+
+// the transitions an issue can take arrived
+type transitionsEvent struct {
+	issueID     string
+	transitions []Transition
+}
+
+// the transition went through, here's the issue as it is now
+type transitionedEvent struct {
+	issueID string
+	issue   *jira.Issue
+}
+
+// jira wants something different, e.g. a field we didn't ask for
+type transitionRejectedEvent struct {
+	issueID string
+	err     error
+}
+
+// the user wants to move an issue along its workflow
+type TransitionIssueMsg struct {
+	IssueID string
+}
+
+// an issue changed. views showing it swap the new one in where the old one
+// was.
+type IssueUpdatedMsg struct {
+	Issue *jira.Issue
+}
+
+func NewTransitionPicker(jiraData Backend) TransitionPicker {
+	return TransitionPicker{jiraData: jiraData}
+}
+
+// start over for another issue
+func (p TransitionPicker) Open(issueID string) (TransitionPicker, tea.Cmd) {
+	p = TransitionPicker{jiraData: p.jiraData, width: p.width, issueID: issueID, open: true, busy: true}
+	return p, p.fetchTransitions()
+}
+
+func (p TransitionPicker) Focused() bool {
+	return p.open
+}
+
+func (p TransitionPicker) fetchTransitions() tea.Cmd {
+	return request(func() (tea.Msg, error) {
+		transitions, err := p.jiraData.GetTransitions(p.issueID)
+		return transitionsEvent{issueID: p.issueID, transitions: transitions}, err
+	})
+}
+
+func (p TransitionPicker) doTransition() tea.Cmd {
+	issueID, transitionID, values := p.issueID, p.transition.ID, p.values
+	return change(func() (tea.Msg, error) {
+		err := p.jiraData.DoTransition(issueID, transitionID, values)
+		// jira wants something else filled in
+		if reason, ok := rejected(err); ok {
+			return transitionRejectedEvent{issueID: issueID, err: reason}, nil
+		}
+		return nil, err
+	}, request(func() (tea.Msg, error) {
+		issue, err := p.jiraData.GetIssue(issueID)
+		return transitionedEvent{issueID: issueID, issue: issue}, err
+	}))
+}
+
+func (p TransitionPicker) Update(msg tea.Msg) (TransitionPicker, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+
+	case transitionsEvent:
+		if p.open && msg.issueID == p.issueID {
+			p.transitions = msg.transitions
+			p.busy = false
+		}
+
+	// the issue changed either way, but the picker may have moved on to
+	// another one since
+	case transitionedEvent:
+		if msg.issueID == p.issueID {
+			p.open = false
+		}
+		return p, func() tea.Msg { return IssueUpdatedMsg{Issue: msg.issue} }
+
+	// back to the list, with jira's reason underneath
+	case transitionRejectedEvent:
+		if msg.issueID != p.issueID {
+			break
+		}
+		p.transition, p.fields, p.values, p.cursor = nil, nil, nil, 0
+		p.busy = false
+		p.err = msg.err
+
+	case tea.KeyMsg:
+		if !p.open || p.busy {
+			if key.Matches(msg, keymap.Keys.Back) {
+				p.open = false
+			}
+			break
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			// back out of filling in fields, or out of the picker
+			if p.transition != nil {
+				p.transition, p.fields, p.values, p.cursor, p.err = nil, nil, nil, 0, nil
+			} else {
+				p.open = false
+			}
+		case key.Matches(msg, keymap.Keys.Up):
+			p.cursor = max(p.cursor-1, 0)
+		case key.Matches(msg, keymap.Keys.Down):
+			p.cursor = min(p.cursor+1, max(len(p.options())-1, 0))
+		case key.Matches(msg, keymap.Keys.Enter):
+			return p.choose()
+		}
+	}
+
+	return p, nil
+}
+
+// take whatever the cursor is on, and transition once nothing's left to ask
+func (p TransitionPicker) choose() (TransitionPicker, tea.Cmd) {
+	if p.cursor >= len(p.options()) {
+		return p, nil
+	}
+
+	if p.transition == nil {
+		transition := p.transitions[p.cursor]
+		p.transition = &transition
+		p.values = make(map[string]string)
+		p.fields = make([]string, 0)
+		// only fields with a fixed set of values can be picked from a list;
+		// jira will say if it wants anything else
+		for id, field := range transition.Fields {
+			if field.Required && len(field.AllowedValues) > 0 {
+				p.fields = append(p.fields, id)
+			}
+		}
+		slices.Sort(p.fields)
+	} else {
+		field := p.transition.Fields[p.fields[0]]
+		p.values[p.fields[0]] = field.AllowedValues[p.cursor].ID
+		p.fields = p.fields[1:]
+	}
+	p.cursor = 0
+
+	if len(p.fields) > 0 {
+		return p, nil
+	}
+	p.busy = true
+	p.err = nil
+	return p, p.doTransition()
+}
+
+// transitions, or the values of the field being filled in
+func (p TransitionPicker) options() []string {
+	options := make([]string, 0)
+	if p.transition == nil {
+		for _, transition := range p.transitions {
+			// transitions are often just named after where they go
+			if transition.Name == transition.To.Name {
+				options = append(options, transition.Name)
+			} else {
+				options = append(options, fmt.Sprintf("%s → %s", transition.Name, transition.To.Name))
+			}
+		}
+		return options
+	}
+	if len(p.fields) == 0 {
+		return options
+	}
+	for _, value := range p.transition.Fields[p.fields[0]].AllowedValues {
+		options = append(options, cmp.Or(value.Name, value.Value))
+	}
+	return options
+}
+
+func (p TransitionPicker) View() string {
+	title := "transition " + p.issueID
+	if p.transition != nil && len(p.fields) > 0 {
+		title = fmt.Sprintf("%s → %s: %s", p.issueID, p.transition.To.Name, p.transition.Fields[p.fields[0]].Name)
+	}
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(title)}
+
+	switch {
+	case p.busy && p.transitions == nil:
+		lines = append(lines, jqlHintStyle.Render("fetching transitions..."))
+	case p.busy:
+		lines = append(lines, jqlHintStyle.Render("transitioning..."))
+	case len(p.options()) == 0:
+		lines = append(lines, jqlHintStyle.Render("nowhere to go from here"))
+	default:
+		for i, option := range p.options() {
+			if i == p.cursor {
+				lines = append(lines, pickerCursorStyle.Render("> "+option))
+			} else {
+				lines = append(lines, "  "+option)
+			}
+		}
+	}
+
+	if p.err != nil {
+		lines = append(lines, jqlErrorStyle.Width(p.width).Render(p.err.Error()))
+	}
+	lines = append(lines, jqlHintStyle.Render(strings.Join([]string{keymap.Does("pick", keymap.Keys.Enter), keymap.Does("move", keymap.Keys.Up, keymap.Keys.Down), keymap.Does("back", keymap.Keys.Back)}, " • ")))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,113 @@
+package jira
+
+import (
+	"slices"
+	"sync/atomic"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+var lastViewID atomic.Int64
+
+// each view gets an id so that pages land in the view that asked for them,
+// even if another view of the same kind is around
+func nextViewID() int {
+	return int(lastViewID.Add(1))
+}
+
+// views that stream in pages report how far along they are, for the
+// statusbar
+type Loader interface {
+	// loaded so far, total expected (0 if jira didn't say), and whether
+	// there's anything left to fetch
+	Progress() (loaded int, total int, done bool)
+}
+
+// rows that fit in height, after the table's borders, header and footer
+func tablePageSize(height int) int {
+	return max(height-6, 1)
+}
+
+// tables move and filter with the keymap's keys, and page with left and right
+// too
+func tableKeyMap() table.KeyMap {
+	keys := table.DefaultKeyMap()
+	keys.RowUp, keys.RowDown, keys.Filter = keymap.Keys.Up, keymap.Keys.Down, keymap.Keys.Search
+	// bottom is the last row, not the start of the last page, so updateTable
+	// sees to it
+	keys.PageFirst, keys.PageLast = keymap.Keys.Top, key.NewBinding(key.WithDisabled())
+	keys.PageUp = key.NewBinding(key.WithKeys(slices.Concat(keymap.Keys.Left.Keys(), keymap.Keys.PageUp.Keys())...))
+	keys.PageDown = key.NewBinding(key.WithKeys(slices.Concat(keymap.Keys.Right.Keys(), keymap.Keys.PageDown.Keys())...))
+	return keys
+}
+
+// pass msg on to a table, taking it to its last row on bottom
+func updateTable(t table.Model, msg tea.Msg) (table.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && !t.GetIsFilterInputFocused() && key.Matches(msg, keymap.Keys.Bottom) {
+		return t.WithHighlightedRow(len(t.GetVisibleRows()) - 1), nil
+	}
+	return t.Update(msg)
+}
+
+// what a table does by name, the way its keys do it: moving around it, and
+// filtering its rows. up and down wrap around, like they do in the table.
+func doTable(t table.Model, action string) (table.Model, bool) {
+	rows := max(len(t.GetVisibleRows()), 1)
+	switch action {
+	case "up":
+		return t.WithHighlightedRow((t.GetHighlightedRowIndex() - 1 + rows) % rows), true
+	case "down":
+		return t.WithHighlightedRow((t.GetHighlightedRowIndex() + 1) % rows), true
+	case "top":
+		return t.WithHighlightedRow(0), true
+	case "bottom":
+		return t.WithHighlightedRow(rows - 1), true
+	case "left", "page_up":
+		return t.PageUp(), true
+	case "right", "page_down":
+		return t.PageDown(), true
+	case "search":
+		return t.StartFilterTyping(), true
+	}
+	return t, false
+}
+
+// viewports scroll with the keymap's keys, and page with their own too
+func scrollKeyMap() viewport.KeyMap {
+	keys := viewport.DefaultKeyMap()
+	keys.Up, keys.Down = keymap.Keys.Up, keymap.Keys.Down
+	keys.PageUp = key.NewBinding(key.WithKeys(slices.Concat(keymap.Keys.PageUp.Keys(), keys.PageUp.Keys())...))
+	keys.PageDown = key.NewBinding(key.WithKeys(slices.Concat(keymap.Keys.PageDown.Keys(), keys.PageDown.Keys())...))
+	return keys
+}
+
+// views that do actions by their names, so that a key, the palette and the
+// : prompt all end up doing the same thing. done is false for actions the
+// view doesn't do.
+type Doer interface {
+	Do(action string) (view tea.Model, cmd tea.Cmd, done bool)
+}
+
+// views with a text input say when it has focus, so that global keys like
+// quit and back don't steal its keystrokes
+type Capturer interface {
+	Capturing() bool
+}
+
+// the accent color was changed in the config, so views drawn in it switch
+// to the new one
+type AccentColorMsg struct {
+	Color lipgloss.Color
+}
+
+// keymap.Keys was changed in the config, so tables and viewports move with
+// the new keys
+type KeysChangedMsg struct{}
+
+// MarkdownTheme was flipped, so issues are drawn again in it
+type ThemeChangedMsg struct{}
@@ -0,0 +1,161 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// what the bottom of the issue view shows
+type issueTab int
+
+const (
+	commentsTab issueTab = iota
+	worklogTab
+)
+
+type durationUnit struct {
+	suffix  string
+	seconds int
+}
+
+// jira counts working time, so a day is 8 hours and a week is 5 days, unless
+// an admin changed it
+var durationUnits = []durationUnit{
+	{"w", 5 * 8 * 60 * 60},
+	{"d", 8 * 60 * 60},
+	{"h", 60 * 60},
+	{"m", 60},
+}
+
+// seconds in a duration written the way jira takes them, e.g. "1h 30m",
+// "2d" or "1.5h"
+func parseWorklogDuration(duration string) (int, error) {
+	words := strings.Fields(duration)
+	if len(words) == 0 {
+		return 0, errors.New("no duration")
+	}
+	seconds := 0.0
+	for _, word := range words {
+		unit := slices.IndexFunc(durationUnits, func(u durationUnit) bool { return strings.HasSuffix(word, u.suffix) })
+		if unit < 0 {
+			return 0, fmt.Errorf("%q needs a unit: w, d, h or m", word)
+		}
+		n, err := strconv.ParseFloat(strings.TrimSuffix(word, durationUnits[unit].suffix), 64)
+		// ParseFloat also takes NaN and Inf, which aren't lengths of time
+		if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+			return 0, fmt.Errorf("%q isn't a duration", word)
+		}
+		seconds += n * float64(durationUnits[unit].seconds)
+	}
+	return int(seconds), nil
+}
+
+// seconds the way jira writes them, e.g. 5400 is "1h 30m"
+func formatWorklogDuration(seconds int) string {
+	parts := make([]string, 0, len(durationUnits))
+	for _, unit := range durationUnits {
+		if seconds >= unit.seconds {
+			parts = append(parts, fmt.Sprintf("%d%s", seconds/unit.seconds, unit.suffix))
+			seconds %= unit.seconds
+		}
+	}
+	if len(parts) == 0 {
+		return "0m"
+	}
+	return strings.Join(parts, " ")
+}
+
+// an issue's worklogs arrived for a view
+type worklogsEvent struct {
+	view     int
+	worklogs []jira.WorklogRecord
+}
+
+func (i IssueView) fetchWorklogs() tea.Cmd {
+	issueID := i.issueID
+	return request(func() (tea.Msg, error) {
+		worklogs, err := i.jiraData.GetWorklogs(issueID)
+		return worklogsEvent{view: i.id, worklogs: worklogs}, err
+	})
+}
+
+// flip between comments and worklogs. worklogs are only asked for the first
+// time they're shown.
+func (i IssueView) switchTab() (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if i.tab == commentsTab {
+		i.tab = worklogTab
+		if i.worklogs == nil {
+			cmd = i.fetchWorklogs()
+		}
+	} else {
+		i.tab = commentsTab
+	}
+	i.render()
+	return i, cmd
+}
+
+// the tabs' names, with the one showing picked out
+func (i IssueView) renderTabs() string {
+	active := lipgloss.NewStyle().Bold(true).Foreground(i.accentColor)
+	tabs := make([]string, 0, 2)
+	for tab, name := range []string{"Comments", "Worklogs"} {
+		if issueTab(tab) == i.tab {
+			tabs = append(tabs, active.Render(name))
+		} else {
+			tabs = append(tabs, jqlHintStyle.Render(name))
+		}
+	}
+	return strings.Join(tabs, jqlHintStyle.Render(" │ "))
+}
+
+// how much time's been logged and how much is left, then who logged what
+func (i IssueView) renderWorklogs(width int) string {
+	lines := []string{i.renderTabs()}
+	if tracking := i.issue.Fields.TimeTracking; tracking != nil {
+		totals := make([]string, 0, 3)
+		for _, total := range []struct{ name, value string }{
+			{"logged", tracking.TimeSpent},
+			{"remaining", tracking.RemainingEstimate},
+			{"estimated", tracking.OriginalEstimate},
+		} {
+			if total.value != "" {
+				totals = append(totals, fmt.Sprintf("%s %s", total.name, total.value))
+			}
+		}
+		if len(totals) > 0 {
+			lines = append(lines, strings.Join(totals, " • "))
+		}
+	}
+
+	switch {
+	case i.worklogs == nil:
+		lines = append(lines, "loading worklogs...")
+	case len(i.worklogs) == 0:
+		lines = append(lines, "no work logged")
+	}
+	for _, worklog := range i.worklogs {
+		author := "someone"
+		if worklog.Author != nil {
+			author = worklog.Author.DisplayName
+		}
+		w := []string{fmt.Sprintf("%s logged %s", author, worklog.TimeSpent)}
+		if worklog.Started != nil {
+			w = append(w, "started "+time.Time(*worklog.Started).Local().Format(time.RFC1123))
+		}
+		if comment := strings.TrimSpace(worklog.Comment); comment != "" {
+			w = append(w, comment)
+		}
+		lines = append(lines, lightBorder.Width(width-2).Render(strings.Join(w, "\n")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,73 @@
+package jira
+
+import "testing"
+
+func TestParseWorklogDuration(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     int
+		wantErr  bool
+	}{
+		{duration: "30m", want: 30 * 60},
+		{duration: "1h 30m", want: 90 * 60},
+		{duration: "  2h   15m ", want: 135 * 60},
+		{duration: "1.5h", want: 90 * 60},
+		{duration: "0.5d", want: 4 * 60 * 60},
+		{duration: "1w 1d", want: 6 * 8 * 60 * 60},
+		{duration: "0m", want: 0},
+		{duration: "", wantErr: true},
+		{duration: "   ", wantErr: true},
+		{duration: "30", wantErr: true},
+		{duration: "1h 30", wantErr: true},
+		{duration: "h", wantErr: true},
+		{duration: "1h30m", wantErr: true},
+		{duration: "-1h", wantErr: true},
+		{duration: "1h -30m", wantErr: true},
+		{duration: "NaNh", wantErr: true},
+		{duration: "Infm", wantErr: true},
+		{duration: "2y", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.duration, func(t *testing.T) {
+			got, err := parseWorklogDuration(tt.duration)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWorklogDuration(%q) error = %v, want error %v", tt.duration, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseWorklogDuration(%q) = %d, want %d", tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWorklogDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{0, "0m"},
+		{59, "0m"},
+		{-60, "0m"},
+		{60, "1m"},
+		{90 * 60, "1h 30m"},
+		{8 * 60 * 60, "1d"},
+		{9 * 60 * 60, "1d 1h"},
+		{5 * 8 * 60 * 60, "1w"},
+		{6*8*60*60 + 61, "1w 1d 1m"},
+	}
+	for _, tt := range tests {
+		if got := formatWorklogDuration(tt.seconds); got != tt.want {
+			t.Errorf("formatWorklogDuration(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+// what's formatted parses back to the same time, to the minute
+func TestWorklogDurationRoundTrip(t *testing.T) {
+	for _, seconds := range []int{60, 45 * 60, 90 * 60, 8 * 60 * 60, 37*60*60 + 5*60} {
+		formatted := formatWorklogDuration(seconds)
+		if got, err := parseWorklogDuration(formatted); err != nil || got != seconds {
+			t.Errorf("parseWorklogDuration(%q) = %d, %v, want %d", formatted, got, err, seconds)
+		}
+	}
+}
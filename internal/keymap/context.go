@@ -0,0 +1,157 @@
+package keymap
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// somewhere in the app, and the actions that work there at the same time,
+// so they can't share a key. the same key can do different things in
+// different places, e.g. tab is the next field in forms, and flips tabs on
+// an issue.
+type Context struct {
+	where  string   // for saying where keys clash, e.g. "an issue"
+	own    []string // what's particular to it
+	moving []string // what moves around it
+	global []string // what the app sees first
+	short  []string // what's worth showing all the time, of all of those
+}
+
+// what works everywhere but forms and pickers, since the app sees it first
+var global = []string{"help", "back", "quit", "jql", "command", "palette", "profiles", "toggle_theme", "retry"}
+
+// what moves around a table
+var moving = []string{"up", "down", "left", "right", "top", "bottom", "page_up", "page_down"}
+
+var (
+	Boards = Context{
+		where:  "the boards",
+		own:    []string{"enter", "search", "new_issue"},
+		moving: moving,
+		global: global,
+		short:  []string{"enter", "search", "jql", "profiles", "help", "quit"},
+	}
+	Sprints = Context{
+		where:  "a board's sprints",
+		own:    []string{"enter", "search", "backlog", "sprint_state", "new_issue"},
+		moving: moving,
+		global: global,
+		short:  []string{"enter", "backlog", "sprint_state", "back", "help", "quit"},
+	}
+	Issues = Context{
+		where:  "a board's issues",
+		own:    []string{"enter", "search", "transition", "layout", "swimlanes", "new_issue"},
+		moving: moving,
+		global: global,
+		short:  []string{"enter", "transition", "layout", "back", "help", "quit"},
+	}
+	Issue = Context{
+		where:  "an issue",
+		own:    []string{"transition", "edit_field", "log_work", "assign_to_me", "copy_key", "next_tab", "comment", "next_comment", "prev_comment", "edit_comment", "delete_comment"},
+		moving: []string{"up", "down", "top", "bottom", "page_up", "page_down"},
+		global: global,
+		short:  []string{"transition", "comment", "log_work", "edit_field", "back", "help"},
+	}
+	Pickers = Context{
+		where:  "pickers",
+		own:    []string{"enter", "back"},
+		moving: []string{"up", "down"},
+		short:  []string{"enter", "up", "down", "back"},
+	}
+	Forms = Context{
+		where: "forms",
+		own:   []string{"submit", "next_field", "prev_field", "toggle", "enter", "back"},
+		short: []string{"submit", "next_field", "toggle", "back"},
+	}
+)
+
+var contexts = []Context{Boards, Sprints, Issues, Issue, Pickers, Forms}
+
+func (c Context) String() string {
+	return c.where
+}
+
+// everything that works in the context
+func (c Context) actions() []string {
+	return slices.Concat(c.own, c.moving, c.global)
+}
+
+// the names of the actions that work in context, for offering them up in
+// the palette. they're done by name, so they needn't be bound to anything.
+// moving around is least likely to be looked for, so it goes last.
+func (c Context) Available() []string {
+	return slices.Concat(c.own, c.global, c.moving)
+}
+
+// whether the action named name works in the context
+func (c Context) Has(name string) bool {
+	return slices.Contains(c.actions(), name)
+}
+
+// the action msg is the key for, of those that work in context. actions
+// that work in the same place can't share keys, so there's only the one.
+func (k KeyMap) Match(msg tea.KeyMsg, context Context) (string, bool) {
+	for _, name := range context.actions() {
+		if binding, ok := k.Binding(name); ok && key.Matches(msg, binding) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// the bindings for the actions named, in that order
+func (k KeyMap) bindings(names []string) []key.Binding {
+	actions := k.actions()
+	bindings := make([]key.Binding, 0, len(names))
+	for _, name := range names {
+		if i := slices.IndexFunc(actions, func(action action) bool { return action.name == name }); i >= 0 {
+			bindings = append(bindings, *actions[i].binding)
+		}
+	}
+	return bindings
+}
+
+// the keys that work in context, for bubbles' help to show. they're looked
+// up as it's shown, so they're always the ones in use.
+func (k KeyMap) In(context Context) help.KeyMap {
+	return contextHelp{keys: k, context: context}
+}
+
+type contextHelp struct {
+	keys    KeyMap
+	context Context
+}
+
+func (h contextHelp) ShortHelp() []key.Binding {
+	return h.keys.bindings(h.context.short)
+}
+
+// what's particular to the context, then moving around, then the rest
+func (h contextHelp) FullHelp() [][]key.Binding {
+	groups := make([][]key.Binding, 0)
+	for _, names := range [][]string{h.context.own, h.context.moving, h.context.global} {
+		if len(names) > 0 {
+			groups = append(groups, h.keys.bindings(names))
+		}
+	}
+	return groups
+}
+
+// the app's own keys, for help outside of any one place
+func (k KeyMap) ShortHelp() []key.Binding {
+	return k.bindings([]string{"help", "back", "quit"})
+}
+
+// every action, grouped the way the keymap is
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.bindings(global),
+		k.bindings([]string{"enter", "search", "sprint_state", "backlog", "new_issue", "layout", "swimlanes", "transition", "edit_field", "next_tab", "log_work", "assign_to_me", "copy_key"}),
+		k.bindings([]string{"comment", "next_comment", "prev_comment", "edit_comment", "delete_comment"}),
+		k.bindings([]string{"submit", "next_field", "prev_field", "toggle"}),
+		k.bindings(moving),
+	}
+}
@@ -0,0 +1,56 @@
+package keymap
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMatch(t *testing.T) {
+	k, _ := Remap("vim", map[string][]string{"copy_key": {}})
+	tests := []struct {
+		key     tea.KeyMsg
+		context Context
+		want    string // nothing if it's no action's
+	}{
+		{Press("t"), Issues, "transition"},
+		{tea.KeyMsg{Type: tea.KeyEnter}, Boards, "enter"},
+		{tea.KeyMsg{Type: tea.KeyDown}, Issue, "down"},
+		{Press("j"), Issue, "down"},
+		{SequenceMsg([]string{"g", "g"}), Sprints, "top"},
+		{SequenceMsg([]string{"space", "t"}), Issue, "transition"},
+		{Press("q"), Issue, "quit"},
+		// only what works there
+		{Press("b"), Boards, ""},
+		{Press("b"), Sprints, "backlog"},
+		{Press("q"), Forms, ""},
+		{tea.KeyMsg{Type: tea.KeyTab}, Forms, "next_field"},
+		{tea.KeyMsg{Type: tea.KeyTab}, Issue, "next_tab"},
+		// unbound
+		{Press("y"), Issue, ""},
+	}
+	for _, tt := range tests {
+		got, ok := k.Match(tt.key, tt.context)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Match(%q, %s) = %q, %v, want %q", tt.key, tt.context, got, ok, tt.want)
+		}
+	}
+}
+
+// the palette offers what works, whether it's bound or not
+func TestAvailable(t *testing.T) {
+	available := Issue.Available()
+	for _, name := range []string{"copy_key", "command", "transition", "page_down"} {
+		if !slices.Contains(available, name) {
+			t.Errorf("Issue.Available() = %v, want %s in it", available, name)
+		}
+	}
+	if slices.Contains(available, "backlog") {
+		t.Errorf("Issue.Available() = %v, want backlog left out, since it's for sprints", available)
+	}
+	// moving around comes last
+	if last := available[len(available)-1]; !slices.Contains(Issue.moving, last) {
+		t.Errorf("Issue.Available() ends with %s, want a way of moving around", last)
+	}
+}
@@ -1,20 +1,70 @@
 package keymap
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
 
 type KeyMap struct {
 	// standard movement between app pages
 
-	Quit  key.Binding
-	Enter key.Binding
-	Back  key.Binding
-	Help  key.Binding
+	Quit    key.Binding
+	Enter   key.Binding
+	Back    key.Binding
+	Help    key.Binding
+	Retry   key.Binding // re-run whatever request just failed
+	JQL     key.Binding // search with arbitrary jql
+	Search  key.Binding // filter the rows of a table
+	Command key.Binding // run an action by its name, vim style
+	Palette key.Binding // fuzzy find any action, and jump anywhere
+
+	Profiles    key.Binding // switch to another jira instance
+	ToggleTheme key.Binding // flip issues between dark and light markdown
+
+	// sprints
+	SprintState key.Binding // cycle which sprint states are shown
+	Backlog     key.Binding // issues that aren't in a sprint
+
+	// issues
+	NewIssue   key.Binding // fill in a form to make one
+	Layout     key.Binding // show the issues in the board's columns
+	Swimlanes  key.Binding // cycle what the board's cards are grouped by
+	Transition key.Binding // move an issue to another status
+	EditField  key.Binding // change a field in the details pane
+	NextTab    key.Binding // flip between an issue's comments and worklogs
+	LogWork    key.Binding // log time spent on an issue
+	AssignToMe key.Binding
+	CopyKey    key.Binding // put the issue's key on the clipboard
+
+	// comments on an issue
+	NextComment   key.Binding
+	PrevComment   key.Binding
+	Comment       key.Binding // write a new one in $EDITOR
+	EditComment   key.Binding
+	DeleteComment key.Binding
+
+	// forms
+	NextField key.Binding
+	PrevField key.Binding
+	Toggle    key.Binding // pick or unpick one of many
+	Submit    key.Binding
 
-	// moving inside a page?
-	// Up   key.Binding
-	// Down key.Binding
+	// moving inside a page
+	Up       key.Binding
+	Down     key.Binding
+	Left     key.Binding
+	Right    key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+
+	Leader string // what <leader> stands for in sequences like "<leader> t"
+	Counts bool   // whether a number before a movement repeats it, e.g. 5j
 }
 
+// the keys every action has unless the config says otherwise
 var DefaultKeyMap = KeyMap{
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
@@ -22,7 +72,7 @@ var DefaultKeyMap = KeyMap{
 	),
 	Enter: key.NewBinding(
 		key.WithKeys("enter"),
-		key.WithHelp("enter", "enter"),
+		key.WithHelp("enter", "select"),
 	),
 	Back: key.NewBinding(
 		key.WithKeys("esc"),
@@ -32,4 +82,157 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("?"),
 		key.WithHelp("?", "help"),
 	),
+	Retry: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "retry"),
+	),
+	JQL: key.NewBinding(
+		key.WithKeys(":"),
+		key.WithHelp(":", "jql search"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	Command: key.NewBinding(
+		key.WithHelp("", "command"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "command palette"),
+	),
+	Profiles: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "switch profile"),
+	),
+	ToggleTheme: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "toggle theme"),
+	),
+	SprintState: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "filter by state"),
+	),
+	Backlog: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "backlog"),
+	),
+	NewIssue: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new issue"),
+	),
+	Layout: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "board layout"),
+	),
+	Swimlanes: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "swimlanes"),
+	),
+	Transition: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "transition"),
+	),
+	EditField: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "edit fields"),
+	),
+	NextTab: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "comments/worklogs"),
+	),
+	LogWork: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "log work"),
+	),
+	AssignToMe: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "assign to me"),
+	),
+	CopyKey: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy key"),
+	),
+	NextComment: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next comment"),
+	),
+	PrevComment: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous comment"),
+	),
+	Comment: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "comment"),
+	),
+	EditComment: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit comment"),
+	),
+	DeleteComment: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete comment"),
+	),
+	NextField: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next field"),
+	),
+	PrevField: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous field"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
+	Submit: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "submit"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Left: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "left"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "right"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("home", "g"),
+		key.WithHelp("home/g", "top"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("end", "G"),
+		key.WithHelp("end/G", "bottom"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdown", "page down"),
+	),
+	Leader: "space",
+}
+
+// the keys in use: the defaults, with the config's keys section over them
+var Keys = DefaultKeyMap
+
+// what bindings do, with their keys as they're bound, for hints like
+// "enter: pick" or "↑/k/↓/j: move"
+func Does(what string, bindings ...key.Binding) string {
+	keys := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		keys = append(keys, binding.Help().Key)
+	}
+	return strings.Join(keys, "/") + ": " + what
 }
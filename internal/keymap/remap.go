@@ -0,0 +1,240 @@
+package keymap
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// an action, by the name it goes by in the config's keys section
+type action struct {
+	name    string
+	binding *key.Binding
+}
+
+// every action, in the order they're checked
+func (k *KeyMap) actions() []action {
+	return []action{
+		{"quit", &k.Quit},
+		{"enter", &k.Enter},
+		{"back", &k.Back},
+		{"help", &k.Help},
+		{"retry", &k.Retry},
+		{"jql", &k.JQL},
+		{"search", &k.Search},
+		{"command", &k.Command},
+		{"palette", &k.Palette},
+		{"profiles", &k.Profiles},
+		{"toggle_theme", &k.ToggleTheme},
+		{"sprint_state", &k.SprintState},
+		{"backlog", &k.Backlog},
+		{"new_issue", &k.NewIssue},
+		{"layout", &k.Layout},
+		{"swimlanes", &k.Swimlanes},
+		{"transition", &k.Transition},
+		{"edit_field", &k.EditField},
+		{"next_tab", &k.NextTab},
+		{"log_work", &k.LogWork},
+		{"assign_to_me", &k.AssignToMe},
+		{"copy_key", &k.CopyKey},
+		{"next_comment", &k.NextComment},
+		{"prev_comment", &k.PrevComment},
+		{"comment", &k.Comment},
+		{"edit_comment", &k.EditComment},
+		{"delete_comment", &k.DeleteComment},
+		{"next_field", &k.NextField},
+		{"prev_field", &k.PrevField},
+		{"toggle", &k.Toggle},
+		{"submit", &k.Submit},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"left", &k.Left},
+		{"right", &k.Right},
+		{"top", &k.Top},
+		{"bottom", &k.Bottom},
+		{"page_up", &k.PageUp},
+		{"page_down", &k.PageDown},
+	}
+}
+
+// every action's name, for the config's keys section
+func Names() []string {
+	names := make([]string, 0)
+	for _, action := range Keys.actions() {
+		names = append(names, action.name)
+	}
+	return names
+}
+
+// the keys the action named name is bound to, if there's such an action
+func (k KeyMap) Binding(name string) (key.Binding, bool) {
+	for _, action := range k.actions() {
+		if action.name == name {
+			return *action.binding, true
+		}
+	}
+	return key.Binding{}, false
+}
+
+// ready-made sets of keys, bound over the defaults before the config's own
+var Presets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"top":        {"home", "g g"},
+		"bottom":     {"end", "G"},
+		"page_up":    {"pgup", "ctrl+u"},
+		"page_down":  {"pgdown", "ctrl+d"},
+		"command":    {":"},
+		"jql":        {"<leader> /"},
+		"transition": {"t", "<leader> t"},
+		"comment":    {"c", "<leader> c"},
+		"log_work":   {"L", "<leader> l"},
+		"new_issue":  {"n", "<leader> n"},
+		"edit_field": {"f", "<leader> f"},
+		"profiles":   {"P", "<leader> p"},
+	},
+}
+
+// the presets' names, sorted
+func PresetNames() []string {
+	return slices.Sorted(maps.Keys(Presets))
+}
+
+// something wrong with the config's keys section
+type Problem struct {
+	Action  string // the action it's about, as it's named in the config
+	Message string
+}
+
+// how keys are written in the config, for the ones bubbletea spells
+// differently
+var keyAliases = map[string]string{
+	"space": " ",
+}
+
+// how keys are shown in help
+var keyLabels = map[string]string{
+	" ":     "space",
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+func label(bound string) string {
+	if label, ok := keyLabels[bound]; ok {
+		return label
+	}
+	return bound
+}
+
+// the keys in a sequence, or just the one key
+func sequence(bound string) []string {
+	if bound == " " {
+		return []string{"space"}
+	}
+	return strings.Fields(bound)
+}
+
+// whether typing one key gets in the way of the other: they're the same,
+// or one's the start of the other's sequence
+func clash(a string, b string) bool {
+	sa, sb := sequence(a), sequence(b)
+	n := min(len(sa), len(sb))
+	return n > 0 && slices.Equal(sa[:n], sb[:n])
+}
+
+// the defaults, with the preset's keys and then the actions in keys bound
+// to those keys instead. an action with no keys isn't bound to anything, and
+// keys.leader is what <leader> stands for. what's wrong with keys comes back
+// too: actions there aren't, and keys that get in each other's way where
+// they both work.
+func Remap(preset string, keys map[string][]string) (KeyMap, []Problem) {
+	k := DefaultKeyMap
+	problems := make([]Problem, 0)
+	actions := k.actions()
+	names := Names()
+	for _, name := range slices.Sorted(maps.Keys(keys)) {
+		if !slices.Contains(names, name) && name != "leader" {
+			problems = append(problems, Problem{Action: name, Message: fmt.Sprintf("isn't an action; they're %s", strings.Join(names, ", "))})
+		}
+	}
+	k.Counts = preset == "vim"
+	if leader, ok := keys["leader"]; ok {
+		if len(leader) != 1 || strings.TrimSpace(leader[0]) == "" {
+			problems = append(problems, Problem{Action: "leader", Message: "needs one key"})
+		} else {
+			k.Leader = leader[0]
+		}
+	}
+
+	// what the user remapped gets the blame for clashes before the preset
+	remapped := make(map[string]int)
+	for level, layer := range []map[string][]string{Presets[preset], keys} {
+		for _, action := range actions {
+			bound, ok := layer[action.name]
+			if !ok {
+				continue
+			}
+			bound = slices.Clone(bound)
+			remapped[action.name] = level + 1
+			labels := make([]string, 0, len(bound))
+			for i, written := range bound {
+				if alias, ok := keyAliases[written]; ok {
+					bound[i] = alias
+				}
+				bound[i] = strings.ReplaceAll(bound[i], "<leader>", k.Leader)
+				if bound[i] == "" {
+					problems = append(problems, Problem{Action: action.name, Message: "has an empty key"})
+				}
+				labels = append(labels, label(bound[i]))
+			}
+			*action.binding = key.NewBinding(
+				key.WithKeys(bound...),
+				key.WithHelp(strings.Join(labels, "/"), action.binding.Help().Desc),
+			)
+		}
+	}
+
+	// two actions whose keys clash, where they both work, is reported once,
+	// on the one remapped last
+	bindings := make(map[string]*key.Binding)
+	for _, action := range actions {
+		bindings[action.name] = action.binding
+	}
+	reported := make(map[[2]string]bool)
+	for _, context := range contexts {
+		live := context.actions()
+		for i, a := range live {
+			for _, b := range live[i+1:] {
+				pair := [2]string{a, b}
+				if reported[pair] || remapped[a]+remapped[b] == 0 {
+					continue
+				}
+				blamed, other := a, b
+				if remapped[a] < remapped[b] {
+					blamed, other = b, a
+				}
+				for _, mine := range bindings[blamed].Keys() {
+					i := slices.IndexFunc(bindings[other].Keys(), func(theirs string) bool { return clash(mine, theirs) })
+					if i < 0 {
+						continue
+					}
+					theirs := bindings[other].Keys()[i]
+					message := fmt.Sprintf("%q is %s's key too, and both work in %s", label(mine), other, context.where)
+					if theirs != mine {
+						message = fmt.Sprintf("%q gets in the way of %s's %q, and both work in %s", label(mine), other, label(theirs), context.where)
+					}
+					problems = append(problems, Problem{Action: blamed, Message: message})
+					reported[pair] = true
+					break
+				}
+			}
+		}
+	}
+
+	return k, problems
+}
@@ -0,0 +1,158 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// every key the map binds, for remapping all of them so that all of them
+// are checked
+func everyKey(k KeyMap) map[string][]string {
+	keys := make(map[string][]string)
+	for _, action := range k.actions() {
+		keys[action.name] = action.binding.Keys()
+	}
+	return keys
+}
+
+func TestPresetsDontClash(t *testing.T) {
+	for _, preset := range PresetNames() {
+		t.Run(preset, func(t *testing.T) {
+			k, problems := Remap(preset, nil)
+			if len(problems) > 0 {
+				t.Fatalf("Remap(%q) = %v, want no problems", preset, problems)
+			}
+			// only what's remapped is checked, so remap everything to
+			// where it already is
+			if _, problems := Remap(preset, everyKey(k)); len(problems) > 0 {
+				t.Errorf("the %s preset's keys clash: %v", preset, problems)
+			}
+		})
+	}
+}
+
+func TestRemapProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset string
+		keys   map[string][]string
+		want   []Problem
+	}{
+		{
+			name: "moved out of the way",
+			keys: map[string][]string{"layout": {"V"}, "transition": {"T"}},
+		},
+		{
+			name: "unbound",
+			keys: map[string][]string{"transition": {}},
+		},
+		{
+			name: "a default's key",
+			keys: map[string][]string{"layout": {"t"}},
+			want: []Problem{{"layout", `"t" is transition's key too, and both work in a board's issues`}},
+		},
+		{
+			name: "only where both work",
+			// e edits comments on an issue, and lays out a board's issues
+			keys: map[string][]string{"layout": {"e"}},
+		},
+		{
+			name: "reported once",
+			keys: map[string][]string{"search": {"n"}},
+			want: []Problem{{"search", `"n" is new_issue's key too, and both work in the boards`}},
+		},
+		{
+			name: "swapped",
+			keys: map[string][]string{"layout": {"t"}, "transition": {"v"}},
+		},
+		{
+			name:   "a single key before a sequence",
+			preset: "vim",
+			keys:   map[string][]string{"swimlanes": {"g"}},
+			want:   []Problem{{"swimlanes", `"g" gets in the way of top's "g g", and both work in a board's issues`}},
+		},
+		{
+			name: "a sequence after a single key",
+			keys: map[string][]string{"swimlanes": {"t x"}},
+			want: []Problem{{"swimlanes", `"t x" gets in the way of transition's "t", and both work in a board's issues`}},
+		},
+		{
+			name:   "the preset's own keys",
+			preset: "vim",
+			keys:   map[string][]string{"top": {"g g"}},
+		},
+		{
+			name: "the leader",
+			keys: map[string][]string{"leader": {"ctrl+x"}, "layout": {"<leader> t"}, "swimlanes": {"ctrl+x"}},
+			// both are the user's, so it's on the first
+			want: []Problem{{"layout", `"ctrl+x t" gets in the way of swimlanes's "ctrl+x", and both work in a board's issues`}},
+		},
+		{
+			name: "what's written wrong",
+			keys: map[string][]string{"leader": {"a", "b"}, "layouts": {"v"}, "layout": {""}},
+			want: []Problem{
+				{"layouts", "isn't an action; they're " + strings.Join(Names(), ", ")},
+				{"leader", "needs one key"},
+				{"layout", "has an empty key"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := Remap(tt.preset, tt.keys)
+			if fmt.Sprint(problems) != fmt.Sprint(tt.want) {
+				t.Errorf("Remap(%q, %v) = %v, want %v", tt.preset, tt.keys, problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapBinds(t *testing.T) {
+	k, _ := Remap("vim", map[string][]string{"leader": {","}, "comment": {"space", "<leader> c"}, "swimlanes": {}})
+	tests := []struct {
+		action string
+		keys   string
+		help   string
+	}{
+		{"comment", "[  , c]", "space/, c"},
+		{"jql", "[, /]", ", /"},
+		{"top", "[home g g]", "home/g g"},
+		{"swimlanes", "[]", ""},
+		{"transition", "[t , t]", "t/, t"},
+		{"up", "[up k]", "↑/k"},
+	}
+	for _, tt := range tests {
+		binding, ok := k.Binding(tt.action)
+		if !ok {
+			t.Fatalf("there's no %s action", tt.action)
+		}
+		if keys := fmt.Sprint(binding.Keys()); keys != tt.keys || binding.Help().Key != tt.help {
+			t.Errorf("%s is bound to %s (%q), want %s (%q)", tt.action, keys, binding.Help().Key, tt.keys, tt.help)
+		}
+	}
+	if !k.Counts {
+		t.Error("the vim preset takes counts, but Counts is false")
+	}
+}
+
+func TestClash(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"g", "g", true},
+		{"g", "g g", true},
+		{"g g", "g", true},
+		{"g g", "g t", false},
+		{"space t", " ", true},
+		{"g", "G", false},
+		{"ctrl+x t", "ctrl+x", true},
+		{"", "g", false},
+	}
+	for _, tt := range tests {
+		if got := clash(tt.a, tt.b); got != tt.want {
+			t.Errorf("clash(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package keymap
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// what a key's called in sequences; tea calls space " ", which can't be told
+// apart from the spaces between keys
+func Name(msg tea.KeyMsg) string {
+	if msg.String() == " " {
+		return "space"
+	}
+	return msg.String()
+}
+
+// whether typed is a whole sequence one of the actions is bound to, or the
+// start of one
+func (k KeyMap) Sequence(typed []string) (whole bool, partial bool) {
+	for _, action := range k.actions() {
+		for _, bound := range action.binding.Keys() {
+			keys := sequence(bound)
+			if len(keys) < 2 || len(typed) > len(keys) || !slices.Equal(keys[:len(typed)], typed) {
+				continue
+			}
+			if len(typed) == len(keys) {
+				whole = true
+			} else {
+				partial = true
+			}
+		}
+	}
+	return whole, partial
+}
+
+// a key standing for a whole sequence. bindings compare keys by name, so it
+// matches any binding with the sequence in it, like a key would.
+func SequenceMsg(typed []string) tea.KeyMsg {
+	return Press(strings.Join(typed, " "))
+}
+
+// a key named bound, whatever it is, for doing what a binding to it does
+func Press(bound string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(bound)}
+}
+
+// whether a count before msg does it that many times. it's only for moving
+// around; anything else happens once.
+func (k KeyMap) Repeats(msg tea.KeyMsg) bool {
+	return key.Matches(msg, k.Up, k.Down, k.Left, k.Right, k.PageUp, k.PageDown, k.NextComment, k.PrevComment)
+}
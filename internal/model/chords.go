@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// no one means to move further than this, and it keeps a mistyped count
+// from locking the app up
+const maxCount = 999
+
+// keys waiting on more keys: a count, or the start of a sequence like g g
+type chords struct {
+	count   int
+	pending []string
+}
+
+// hold on to a key that's a count or could go on to be a sequence, or say
+// which keys to act on. a finished sequence is one key named for the whole
+// sequence, so it matches the bindings it's in like any other key.
+func (c chords) press(msg tea.KeyMsg) (chords, []tea.KeyMsg) {
+	name := keymap.Name(msg)
+	digit := len(name) == 1 && name[0] >= '0' && name[0] <= '9'
+	// 0 on its own isn't a count
+	if keymap.Keys.Counts && len(c.pending) == 0 && digit && (name != "0" || c.count > 0) {
+		c.count = min(c.count*10+int(name[0]-'0'), maxCount)
+		return c, nil
+	}
+
+	typed := append(slices.Clone(c.pending), name)
+	whole, partial := keymap.Keys.Sequence(typed)
+	switch {
+	case partial:
+		c.pending = typed
+		return c, nil
+	case whole:
+		msg = keymap.SequenceMsg(typed)
+	// anything else gives up on what was typed, esc included
+	case len(c.pending) > 0, c.count > 0 && key.Matches(msg, keymap.Keys.Back):
+		return chords{}, nil
+	}
+
+	times := 1
+	if c.count > 0 && keymap.Keys.Repeats(msg) {
+		times = c.count
+	}
+	return chords{}, slices.Repeat([]tea.KeyMsg{msg}, times)
+}
+
+// what's been typed so far, for the statusbar
+func (c chords) String() string {
+	typed := slices.Clone(c.pending)
+	if c.count > 0 {
+		typed = append([]string{fmt.Sprint(c.count)}, typed...)
+	}
+	return strings.Join(typed, " ")
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// use preset's keys for the rest of the test
+func usePreset(t *testing.T, preset string) {
+	t.Helper()
+	keys, problems := keymap.Remap(preset, nil)
+	if len(problems) > 0 {
+		t.Fatal(problems)
+	}
+	before := keymap.Keys
+	keymap.Keys = keys
+	t.Cleanup(func() { keymap.Keys = before })
+}
+
+// keys by the names they have in sequences, e.g. "g" or "esc"
+func keyMsgs(names string) []tea.KeyMsg {
+	msgs := make([]tea.KeyMsg, 0)
+	for _, name := range strings.Fields(names) {
+		switch name {
+		case "esc":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyEsc})
+		case "space":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		default:
+			msgs = append(msgs, keymap.Press(name))
+		}
+	}
+	return msgs
+}
+
+func TestChordsPress(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  string
+		typed   string   // keys, one after another
+		pressed []string // what's acted on, by name
+		waiting string   // what's left for the statusbar
+	}{
+		{"a count repeats a movement", "vim", "5 j", slices.Repeat([]string{"j"}, 5), ""},
+		{"zero on its own isn't a count", "vim", "0", []string{"0"}, ""},
+		{"zero in a count is", "vim", "1 0 j", slices.Repeat([]string{"j"}, 10), ""},
+		{"a count waits for what it's for", "vim", "1 2", nil, "12"},
+		{"counts stop at the most", "vim", "1 2 3 4 5 j", slices.Repeat([]string{"j"}, maxCount), ""},
+		{"a count doesn't repeat anything else", "vim", "5 t", []string{"t"}, ""},
+		{"esc gives up on a count", "vim", "5 esc", nil, ""},
+		{"esc on its own is esc", "vim", "esc", []string{"esc"}, ""},
+		{"a sequence is one key", "vim", "g g", []string{"g g"}, ""},
+		{"a sequence waits for the rest", "vim", "g", nil, "g"},
+		{"a count before a sequence that isn't a movement", "vim", "3 g g", []string{"g g"}, ""},
+		{"a broken sequence drops what was typed", "vim", "g x", nil, ""},
+		{"esc gives up on a sequence", "vim", "g esc", nil, ""},
+		{"the leader starts sequences", "vim", "space t", []string{"space t"}, ""},
+		{"without counts numbers are keys", "default", "5 j", []string{"5", "j"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePreset(t, tt.preset)
+			var c chords
+			var pressed []string
+			for _, msg := range keyMsgs(tt.typed) {
+				var msgs []tea.KeyMsg
+				c, msgs = c.press(msg)
+				for _, msg := range msgs {
+					pressed = append(pressed, keymap.Name(msg))
+				}
+			}
+			if !slices.Equal(pressed, tt.pressed) || c.String() != tt.waiting {
+				t.Errorf("typing %s pressed %v waiting on %q, want %v waiting on %q", tt.typed, pressed, c.String(), tt.pressed, tt.waiting)
+			}
+		})
+	}
+}
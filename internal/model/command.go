@@ -0,0 +1,134 @@
+package model
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// the : prompt, for doing anything by the name of its action, vim style.
+// :jql and :profiles take what to search for and who to switch to.
+type commandLine struct {
+	input textinput.Model
+	err   error
+	width int
+}
+
+// a command was typed in
+type runCommandMsg struct {
+	action string
+	args   string
+}
+
+// what commands go by besides the actions' names
+var commandAliases = map[string]string{
+	"q":       "quit",
+	"profile": "profiles",
+}
+
+func newCommandLine() commandLine {
+	input := textinput.New()
+	input.Prompt = ":"
+	input.ShowSuggestions = true
+	return commandLine{input: input}
+}
+
+// start with nothing typed, suggesting every action
+func (c commandLine) Open() (commandLine, tea.Cmd) {
+	c.input.Reset()
+	c.input.SetSuggestions(slices.Concat(keymap.Names(), slices.Collect(maps.Keys(commandAliases))))
+	c.err = nil
+	return c, c.input.Focus()
+}
+
+func (c commandLine) Focused() bool {
+	return c.input.Focused()
+}
+
+// the command was run, but couldn't do anything
+func (c commandLine) failed(err error) (commandLine, tea.Cmd) {
+	c, cmd := c.Open()
+	c.err = err
+	return c, cmd
+}
+
+func (c commandLine) Update(msg tea.Msg) (commandLine, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.input.Width = max(msg.Width-2, 1)
+		return c, nil
+
+	case tea.KeyMsg:
+		if !c.Focused() {
+			return c, nil
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			c.input.Blur()
+			return c, nil
+		case key.Matches(msg, keymap.Keys.Enter):
+			name, args, _ := strings.Cut(strings.TrimSpace(c.input.Value()), " ")
+			if name == "" {
+				c.input.Blur()
+				return c, nil
+			}
+			if alias, ok := commandAliases[name]; ok {
+				name = alias
+			}
+			if !slices.Contains(keymap.Names(), name) {
+				c.err = fmt.Errorf("there's no command %q", name)
+				return c, nil
+			}
+			c.input.Blur()
+			run := runCommandMsg{action: name, args: strings.TrimSpace(args)}
+			return c, func() tea.Msg { return run }
+		}
+		c.err = nil
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return c, cmd
+}
+
+func (c commandLine) View() string {
+	lines := []string{c.input.View()}
+	if c.err != nil {
+		lines = append(lines, profileErrorStyle.Width(c.width).Render(c.err.Error()))
+	} else {
+		lines = append(lines, profileHintStyle.Render(strings.Join([]string{keymap.Does("run", keymap.Keys.Enter), "tab: complete", keymap.Does("cancel", keymap.Keys.Back)}, " • ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// do what the command's action does, the same as its key would: the app's
+// own actions first, then the view's
+func (m Model) runCommand(msg runCommandMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case msg.action == "jql" && msg.args != "":
+		m.jql, cmd = m.jql.Search(msg.args)
+		return m, cmd
+	case msg.action == "profiles" && msg.args != "":
+		m.profiles, cmd = m.profiles.Open().pick(strings.ToLower(msg.args))
+		return m, cmd
+	}
+	m, cmd, done := m.do(msg.action)
+	if !done {
+		cmd, done = m.router.do(msg.action)
+		m.refreshStatusBar()
+	}
+	if !done {
+		context := viewKeys[m.router.current().state]
+		m.command, cmd = m.command.failed(fmt.Errorf("%s doesn't do anything in %s", msg.action, context))
+	}
+	return m, cmd
+}
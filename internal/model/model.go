@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/config"
 	"github.com/guppy0130/go-jira-tui/internal/jira"
 	"github.com/guppy0130/go-jira-tui/internal/keymap"
 	"github.com/mistakenelf/teacup/statusbar"
@@ -17,61 +18,121 @@ type ViewState string
 
 const (
 	ViewStateBoards      ViewState = "boards"
+	ViewStateSprints     ViewState = "sprints"
 	ViewStateIssues      ViewState = "issues"
 	ViewStateSingleIssue ViewState = "issue"
+	ViewStateKanban      ViewState = "kanban"
+	ViewStateNewIssue    ViewState = "new issue"
 )
 
+// the keys that work in each view, for the help
+var viewKeys = map[ViewState]keymap.Context{
+	ViewStateBoards:      keymap.Boards,
+	ViewStateSprints:     keymap.Sprints,
+	ViewStateIssues:      keymap.Issues,
+	ViewStateSingleIssue: keymap.Issue,
+	ViewStateKanban:      keymap.Issues,
+	ViewStateNewIssue:    keymap.Forms,
+}
+
+// prompts that sit between the header and the body, taking every keystroke
+// while they're open
+type overlay interface {
+	Focused() bool
+	View() string
+}
+
 type Model struct {
-	globalHeight int       // usable height
-	globalWidth  int       // usable width
-	viewState    ViewState // board, issue, sprint, etc.
+	globalHeight int // usable height
+	globalWidth  int // usable width
+
+	router router         // the views we've drilled through, the one showing on top
+	jql    jira.JQLPrompt // shown above the body while it has focus
 
-	boardsView jira.BoardsView
-	boardView  jira.BoardView
+	transitions jira.TransitionPicker // same, for moving an issue along
+	logWork     jira.LogWorkDialog    // and for logging time against one
+	profiles    profilePicker         // and for switching jira instances
+	reload      configReload          // and for what came of the config file changing
+	command     commandLine           // and for running actions by name
+	palette     palette               // and for finding anything to do
 
-	// tables       map[ViewState]table.Model // some table that will get assigned
-	// viewport  viewport.Model  // some issue content??
+	chords chords     // a count or the start of a sequence, waiting on more keys
+	help   help.Model // the view's keys under the body, and all of them on help
 
 	statusBar statusbar.Model // statusbar
-	JiraData  jira.JiraData   // jira data
+	JiraData  jira.Backend    // jira data
+	failure   *jira.ErrorMsg  // last failed request, shown until dismissed
+	config    config.Config   // config.Profile is the profile JiraData signed into
 
 	AccentColor lipgloss.Color
-	// breadcrumbs  []breadcrumb    // supports going back with esc
 }
 
-func NewModel(jiraData jira.JiraData, accentColor lipgloss.Color) Model {
+func NewModel(jiraData jira.Backend, conf config.Config) Model {
 	m := Model{
-		JiraData:    jiraData,
-		AccentColor: accentColor,
-		viewState:   ViewStateBoards,
+		config:      conf,
+		AccentColor: conf.AccentColor,
+		profiles:    newProfilePicker(conf),
+		command:     newCommandLine(),
+		palette:     newPalette(),
+		help:        help.New(),
 	}
+	m.reset(jiraData)
+	m.statusBar = newStatusBar(m.AccentColor)
+	m.refreshStatusBar()
+	return m
+}
+
+func newStatusBar(accentColor lipgloss.Color) statusbar.Model {
 	sbAccent := statusbar.ColorConfig{
 		Foreground: lipgloss.AdaptiveColor{Dark: "FG", Light: "BG"},
-		Background: lipgloss.AdaptiveColor{Dark: string(m.AccentColor), Light: string(m.AccentColor)},
+		Background: lipgloss.AdaptiveColor{Dark: string(accentColor), Light: string(accentColor)},
 	}
 	sbStandard := statusbar.ColorConfig{
 		Foreground: lipgloss.AdaptiveColor{Dark: "FG", Light: "BG"},
 		Background: lipgloss.AdaptiveColor{Dark: "BG", Light: "FG"},
 	}
-	m.statusBar = statusbar.New(sbAccent, sbStandard, sbAccent, sbAccent)
-	return m
+	return statusbar.New(sbAccent, sbStandard, sbAccent, sbAccent)
 }
 
-// func (m *Model) updateBoards() tea.Msg {
-// 	m.boardsView = jira.NewBoardsView(m.JiraData)
-// 	return m.boardView
-// }
+// start over from the boards, with everything talking to jiraData
+func (m *Model) reset(jiraData jira.Backend) {
+	m.JiraData = jiraData
+	m.router = newRouter(ViewStateBoards, jira.NewBoardsView(jiraData, 0))
+	m.jql = jira.NewJQLPrompt(jiraData)
+	m.transitions = jira.NewTransitionPicker(jiraData)
+	m.logWork = jira.NewLogWorkDialog(jiraData, m.AccentColor)
+	m.palette = m.palette.forgetBoards()
+	m.failure = nil
+}
 
-func (m Model) Init() tea.Cmd {
-	return func() tea.Msg {
-		m.boardsView = jira.NewBoardsView(m.JiraData, m.globalWidth)
-		return m.boardView.Init
+// the statusbar shows how we got here, how much is still loading, or that
+// something went wrong
+func (m *Model) refreshStatusBar() {
+	current := m.router.current()
+	state, detail := string(current.state), m.router.breadcrumbs()
+	if loader, ok := current.view.(jira.Loader); ok {
+		if loaded, total, done := loader.Progress(); !done {
+			progress := fmt.Sprintf("loading %d", loaded)
+			if total > 0 {
+				progress = fmt.Sprintf("loading %d of %d", loaded, total)
+			}
+			detail = fmt.Sprintf("%s • %s", detail, progress)
+		}
+	}
+	if typed := m.chords.String(); typed != "" {
+		detail = fmt.Sprintf("%s • keys: %s", detail, typed)
+	}
+	if m.failure != nil {
+		state, detail = "error", keymap.Does("retry", keymap.Keys.Retry)+", "+keymap.Does("dismiss", keymap.Keys.Back)
 	}
+	m.statusBar.SetContent(state, detail, m.JiraData.Self().DisplayName, fmt.Sprintf("%s • %s", m.config.Profile, m.JiraData.Host()))
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	cmds := make([]tea.Cmd, 0)
+func (m Model) Init() tea.Cmd {
+	return m.router.current().view.Init()
+}
 
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	slog.Debug("update", "msg", msg)
 
 	switch msg := msg.(type) {
@@ -81,52 +142,310 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.globalHeight = msg.Height
 		m.globalWidth = msg.Width
 		m.statusBar.SetSize(msg.Width)
+		m.help.Width = msg.Width
+		// views only get the space between the header and the statusbar
+		msg.Height = m.bodyHeight()
+		return m.updateViews(msg)
+
+	// some request failed; hold on to it so it can be retried
+	case jira.ErrorMsg:
+		m.failure = &msg
+
+	// a board was picked. kanban boards don't have sprints, so go straight
+	// to their issues
+	case jira.OpenBoardMsg:
+		if msg.Board.Type == "kanban" {
+			return m.open(ViewStateIssues, jira.NewBoardView(m.JiraData, msg.Board, m.globalWidth))
+		}
+		return m.open(ViewStateSprints, jira.NewSprintsView(m.JiraData, msg.Board, m.globalWidth))
+
+	// a sprint was picked, list its issues
+	case jira.OpenSprintMsg:
+		return m.open(ViewStateIssues, jira.NewSprintView(m.JiraData, msg.Sprint, m.globalWidth))
+
+	// the backlog of a board with sprints
+	case jira.OpenBacklogMsg:
+		return m.open(ViewStateIssues, jira.NewBacklogView(m.JiraData, msg.Board, m.globalWidth))
+
+	// the same issues, in the board's columns
+	case jira.OpenKanbanMsg:
+		return m.open(ViewStateKanban, jira.NewKanbanView(m.JiraData, msg.BoardID, msg.Title, msg.Fetch, m.AccentColor, m.globalWidth, m.bodyHeight()))
+
+	// a query ran, list what it found
+	case jira.OpenSearchMsg:
+		return m.open(ViewStateIssues, jira.NewSearchView(m.JiraData, msg.JQL, msg.Page, m.globalWidth))
+
+	// pick where an issue goes next
+	case jira.TransitionIssueMsg:
+		var cmd tea.Cmd
+		m.transitions, cmd = m.transitions.Open(msg.IssueID)
+		return m, cmd
+
+	// log time against an issue
+	case jira.LogWorkMsg:
+		var cmd tea.Cmd
+		m.logWork, cmd = m.logWork.Open(msg.IssueID)
+		return m, cmd
+
+	// signed into another jira; what was open belongs to the old one, so
+	// start over from its boards. a sign in nobody's waiting for anymore
+	// changes nothing.
+	case profileConnectedMsg:
+		if !m.profiles.waitingFor(msg.attempt) && !m.reload.waitingFor(msg.attempt) {
+			return m, nil
+		}
+		m.profiles, _ = m.profiles.Update(msg)
+		m.reload, _ = m.reload.Update(msg)
+		m.config.Profile = msg.profile
+		m.reset(msg.jiraData)
+		m, cmd := m.updateViews(tea.WindowSizeMsg{Width: m.globalWidth, Height: m.bodyHeight()})
+		return m, tea.Batch(cmd, m.router.current().view.Init())
+
+	// the config file was saved
+	case ConfigChangedMsg:
+		return m.reloadConfig(msg)
+
+	// fill in a new issue
+	case jira.NewIssueMsg:
+		return m.open(ViewStateNewIssue, jira.NewIssueForm(m.JiraData, msg.BoardID, m.AccentColor, m.globalWidth, m.bodyHeight()))
+
+	// the form's done with, show what it made in its place
+	case jira.IssueCreatedMsg:
+		if m.router.current().state == ViewStateNewIssue {
+			m.router.pop()
+		}
+		return m.open(ViewStateSingleIssue, jira.NewIssueView(m.JiraData, msg.IssueID, m.AccentColor, m.globalWidth, m.bodyHeight()))
+
+	// a view that takes esc for itself is done
+	case jira.CloseMsg:
+		m.router.pop()
+		m.refreshStatusBar()
+		return m, nil
+
+	// an issue was picked, show all of it
+	case jira.OpenIssueMsg:
+		return m.open(ViewStateSingleIssue, jira.NewIssueView(m.JiraData, msg.IssueID, m.AccentColor, m.globalWidth, m.bodyHeight()))
 
 	// handle keystrokes
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, keymap.DefaultKeyMap.Quit):
-			return m, tea.Quit
+		// the jql prompt, the transition picker and the log work dialog sit
+		// above every view, so they go first
+		if m.jql.Focused() || m.transitions.Focused() || m.logWork.Focused() || m.profiles.Focused() || m.reload.Focused() || m.command.Focused() || m.palette.Focused() {
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
+			}
+			var cmd tea.Cmd
+			switch {
+			// it pops up by itself, so it's on top
+			case m.reload.Focused():
+				m.reload, cmd = m.reload.Update(msg)
+			case m.command.Focused():
+				m.command, cmd = m.command.Update(msg)
+			case m.palette.Focused():
+				m.palette, cmd = m.palette.Update(msg)
+			case m.jql.Focused():
+				m.jql, cmd = m.jql.Update(msg)
+			case m.transitions.Focused():
+				m.transitions, cmd = m.transitions.Update(msg)
+			case m.profiles.Focused():
+				m.profiles, cmd = m.profiles.Update(msg)
+			default:
+				m.logWork, cmd = m.logWork.Update(msg)
+			}
+			return m, cmd
+		}
+
+		// while a view is taking text (e.g. a table filter), keys are its
+		// to have; only ctrl+c gets out
+		if capturer, ok := m.router.current().view.(jira.Capturer); ok && capturer.Capturing() {
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
+			}
+			break
+		}
+
+		// counts and sequences like g g take a few keys to come to anything
+		var pressed []tea.KeyMsg
+		m.chords, pressed = m.chords.press(msg)
+		m.refreshStatusBar()
+		cmds := make([]tea.Cmd, 0, len(pressed))
+		for _, msg := range pressed {
+			var cmd tea.Cmd
+			m, cmd = m.pressed(msg)
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	// a command was typed in at the : prompt
+	case runCommandMsg:
+		return m.runCommand(msg)
+	}
+
+	return m.updateViews(msg)
+}
+
+// do what a key does: the app's own actions first, then the view's
+func (m Model) pressed(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if action, ok := keymap.Keys.Match(msg, viewKeys[m.router.current().state]); ok {
+		if m, cmd, done := m.do(action); done {
+			return m, cmd
 		}
+	}
+	return m.updateViews(msg)
+}
 
+// do the action named action if it's one of the app's own, whatever view
+// is showing. done is false for the rest, which are up to the view.
+func (m Model) do(action string) (Model, tea.Cmd, bool) {
+	var cmd tea.Cmd
+	switch {
+	case action == "quit":
+		return m, tea.Quit, true
+	case action == "help":
+		m.help.ShowAll = !m.help.ShowAll
+	case m.help.ShowAll && action == "back":
+		m.help.ShowAll = false
+	case action == "jql":
+		m.jql, cmd = m.jql.Open()
+	case action == "command":
+		m.command, cmd = m.command.Open()
+	case action == "palette":
+		m.palette, cmd = m.palette.Open(m.paletteEntries(), m.JiraData)
+	case action == "profiles":
+		m.profiles = m.profiles.Open()
+	case action == "toggle_theme":
+		if jira.MarkdownTheme == "dark" {
+			jira.MarkdownTheme = "light"
+		} else {
+			jira.MarkdownTheme = "dark"
+		}
+		m, cmd = m.updateViews(jira.ThemeChangedMsg{})
+	case m.failure != nil && action == "retry":
+		cmd = m.failure.Retry
+		m.failure = nil
+		m.refreshStatusBar()
+	case m.failure != nil && action == "back":
+		m.failure = nil
+		m.refreshStatusBar()
+	case action == "back":
+		m.router.pop()
+		m.refreshStatusBar()
 	default:
-		slog.Warn("unknown update type", "type", msg)
+		return m, nil, false
 	}
+	return m, cmd, true
+}
 
-	// hand update message to child views in case they need it for something
-	// model, newCmd := m.boardsView.Update(msg)
-	// m.boardsView = model.(jira.BoardsView)
-	// cmds = append(cmds, newCmd)
+// size a freshly made view to fit, put it on top and start it loading
+func (m Model) open(state ViewState, view tea.Model) (Model, tea.Cmd) {
+	view, _ = view.Update(tea.WindowSizeMsg{Width: m.globalWidth, Height: m.bodyHeight()})
+	m.router.push(state, view)
+	m.refreshStatusBar()
+	return m, view.Init()
+}
 
+// hand update message to child views in case they need it for something
+func (m Model) updateViews(msg tea.Msg) (Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, m.router.update(msg))
+	// keystrokes only reach the overlays while they're open, and that's
+	// handled before we get here
+	if _, isKey := msg.(tea.KeyMsg); !isKey {
+		var cmd tea.Cmd
+		m.jql, cmd = m.jql.Update(msg)
+		cmds = append(cmds, cmd)
+		m.transitions, cmd = m.transitions.Update(msg)
+		cmds = append(cmds, cmd)
+		m.logWork, cmd = m.logWork.Update(msg)
+		cmds = append(cmds, cmd)
+		m.profiles, cmd = m.profiles.Update(msg)
+		cmds = append(cmds, cmd)
+		m.reload, cmd = m.reload.Update(msg)
+		cmds = append(cmds, cmd)
+		m.command, cmd = m.command.Update(msg)
+		cmds = append(cmds, cmd)
+		m.palette, cmd = m.palette.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	// pages may have come in, so the progress might have moved
+	m.refreshStatusBar()
 	return m, tea.Batch(cmds...)
 }
 
+// what's left between the header and the keys under the body
+func (m Model) bodyHeight() int {
+	return max(m.globalHeight-m.statusBar.Height-4, 0)
+}
+
 func (m Model) View() string {
 	strings := make([]string, 0)
+	current := m.router.current()
+
 	// the header is what page we're on?
-	strings = append(strings, string(m.viewState))
+	header := string(current.state)
+	if _, ok := current.view.(titled); ok {
+		header = fmt.Sprintf("%s: %s", header, current.title())
+	}
+	strings = append(strings, header)
 
-	// some body
-	body := ""
-	slog.Debug("rendering main app", "viewstate", m.viewState)
-	switch m.viewState {
-	case ViewStateBoards:
-		body = m.boardsView.View()
-	case ViewStateIssues:
-		body = m.boardView.View()
-	default:
-		panic(fmt.Errorf("unable to handle viewState %+v", m.viewState))
+	// errors go above everything else so they're hard to miss
+	bannerHeight := 0
+	if m.failure != nil {
+		banner := renderErrorBanner(*m.failure, m.globalWidth)
+		bannerHeight = lipgloss.Height(banner)
+		strings = append(strings, banner)
+	}
+
+	// the overlays push the body down too
+	for _, overlay := range []overlay{m.reload, m.command, m.palette, m.jql, m.transitions, m.logWork, m.profiles} {
+		if overlay.Focused() {
+			rendered := overlay.View()
+			bannerHeight += lipgloss.Height(rendered)
+			strings = append(strings, rendered)
+		}
 	}
+
+	// as does every key the view has, while they're asked for
+	keys := keymap.Keys.In(viewKeys[current.state])
+	if m.help.ShowAll {
+		rendered := lipgloss.JoinVertical(
+			lipgloss.Left,
+			lipgloss.NewStyle().Bold(true).Render("keys for "+viewKeys[current.state].String()),
+			m.help.FullHelpView(keys.FullHelp()),
+			profileHintStyle.Render(keymap.Does("close", keymap.Keys.Help, keymap.Keys.Back)),
+		)
+		bannerHeight += lipgloss.Height(rendered)
+		strings = append(strings, rendered)
+	}
+
+	// some body
+	slog.Debug("rendering main app", "viewstate", current.state)
 	strings = append(
 		strings,
 		lipgloss.
 			NewStyle().
-			Height(m.globalHeight-m.statusBar.Height-3).
-			MaxHeight(m.globalHeight-m.statusBar.Height-3).Render(body),
+			Height(max(m.bodyHeight()-bannerHeight, 0)).
+			MaxHeight(max(m.bodyHeight()-bannerHeight, 0)).Render(current.view.View()),
 	)
 
-	// the statusbar
-	strings = append(strings, m.statusBar.View())
+	// the keys worth knowing, then the statusbar
+	strings = append(strings, m.help.ShortHelpView(keys.ShortHelp()), m.statusBar.View())
 
 	return lipgloss.JoinVertical(lipgloss.Top, strings...)
 }
+
+var errorBannerStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("9")).
+	Foreground(lipgloss.Color("9")).
+	Padding(0, 1)
+
+func renderErrorBanner(failure jira.ErrorMsg, width int) string {
+	hint := fmt.Sprintf(
+		"%s %s • %s %s",
+		keymap.Keys.Retry.Help().Key, keymap.Keys.Retry.Help().Desc,
+		keymap.Keys.Back.Help().Key, "dismiss",
+	)
+	// the border takes up 2 columns
+	return errorBannerStyle.Width(max(width-2, 0)).Render(failure.Err.Error() + "\n" + hint)
+}
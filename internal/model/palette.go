@@ -0,0 +1,269 @@
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+	"github.com/sahilm/fuzzy"
+)
+
+// how many of the matches are shown at once
+const paletteRows = 8
+
+var paletteMatchStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+
+// finds anything to do by typing a bit of its name: what works where we
+// are, opening a board, saved queries and ones that worked, other profiles. what was run
+// lately comes first.
+type palette struct {
+	input         textinput.Model
+	entries       []paletteEntry // what there is to do here, besides the boards
+	boards        []paletteEntry // nil until they've been fetched
+	loadingBoards bool
+	boardsErr     error
+	found         fuzzy.Matches // of all(), best first
+	cursor        int
+	recent        []string // titles of what was run, most recent first
+	width         int
+}
+
+// something the palette can do, by sending msg
+type paletteEntry struct {
+	title string // what's searched and shown, e.g. "transition" or "open board: TUI"
+	keys  string // what it's bound to, for the actions, or the jql a saved query runs
+	msg   tea.Msg
+}
+
+// the boards came in, so they can be opened from the palette
+type paletteBoardsMsg struct {
+	boards []paletteEntry
+	err    error
+}
+
+func newPalette() palette {
+	input := textinput.New()
+	input.Prompt = "find> "
+	input.Placeholder = "type to find an action, a board, a query or a profile"
+	return palette{input: input}
+}
+
+// start with nothing typed, offering entries and the boards, fetching them
+// the first time
+func (p palette) Open(entries []paletteEntry, jiraData jira.Backend) (palette, tea.Cmd) {
+	p.input.Reset()
+	p.entries, p.cursor = entries, 0
+	p.filter()
+	cmds := []tea.Cmd{p.input.Focus()}
+	if p.boards == nil && !p.loadingBoards {
+		p.loadingBoards, p.boardsErr = true, nil
+		cmds = append(cmds, fetchPaletteBoards(jiraData))
+	}
+	return p, tea.Batch(cmds...)
+}
+
+// signed into another jira, whose boards are different
+func (p palette) forgetBoards() palette {
+	p.boards, p.loadingBoards, p.boardsErr = nil, false, nil
+	return p
+}
+
+func (p palette) Focused() bool {
+	return p.input.Focused()
+}
+
+// every page of boards, since any of them could be the one wanted
+func fetchPaletteBoards(jiraData jira.Backend) tea.Cmd {
+	return func() tea.Msg {
+		boards := make([]paletteEntry, 0)
+		for startAt := 0; ; {
+			page, err := jiraData.GetBoards(startAt)
+			if err != nil {
+				return paletteBoardsMsg{err: err}
+			}
+			for _, board := range page.Values {
+				boards = append(boards, paletteEntry{title: "open board: " + board.Name, msg: jira.OpenBoardMsg{Board: board}})
+			}
+			if page.IsLast || len(page.Values) == 0 {
+				return paletteBoardsMsg{boards: boards}
+			}
+			startAt += len(page.Values)
+		}
+	}
+}
+
+// everything on offer, boards last
+func (p palette) all() []paletteEntry {
+	return slices.Concat(p.entries, p.boards)
+}
+
+// match what's typed against every entry, putting what was run lately first
+// and the rest best match first. with nothing typed, everything matches.
+func (p *palette) filter() {
+	all := p.all()
+	if query := strings.TrimSpace(p.input.Value()); query != "" {
+		titles := make([]string, 0, len(all))
+		for _, entry := range all {
+			titles = append(titles, entry.title)
+		}
+		p.found = fuzzy.Find(query, titles)
+	} else {
+		p.found = make(fuzzy.Matches, 0, len(all))
+		for i, entry := range all {
+			p.found = append(p.found, fuzzy.Match{Str: entry.title, Index: i})
+		}
+	}
+	rank := func(match fuzzy.Match) int {
+		if i := slices.Index(p.recent, match.Str); i >= 0 {
+			return i
+		}
+		return len(p.recent)
+	}
+	slices.SortStableFunc(p.found, func(a, b fuzzy.Match) int {
+		return cmp.Compare(rank(a), rank(b))
+	})
+	p.cursor = min(p.cursor, max(len(p.found)-1, 0))
+}
+
+func (p palette) Update(msg tea.Msg) (palette, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.input.Width = max(msg.Width-lipgloss.Width(p.input.Prompt)-1, 1)
+		return p, nil
+
+	case paletteBoardsMsg:
+		p.loadingBoards = false
+		if msg.err != nil {
+			slog.Warn("couldn't fetch boards for the palette", "err", msg.err)
+			p.boardsErr = msg.err
+			return p, nil
+		}
+		p.boards = msg.boards
+		p.filter()
+		return p, nil
+
+	case tea.KeyMsg:
+		if !p.Focused() {
+			return p, nil
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			p.input.Blur()
+			return p, nil
+		case key.Matches(msg, keymap.Keys.Enter):
+			if len(p.found) == 0 {
+				return p, nil
+			}
+			entry := p.all()[p.found[p.cursor].Index]
+			p.recent = append([]string{entry.title}, slices.DeleteFunc(p.recent, func(title string) bool { return title == entry.title })...)
+			p.input.Blur()
+			return p, func() tea.Msg { return entry.msg }
+		case msg.Type == tea.KeyUp, msg.Type == tea.KeyCtrlP:
+			p.cursor = max(p.cursor-1, 0)
+			return p, nil
+		case msg.Type == tea.KeyDown, msg.Type == tea.KeyCtrlN:
+			p.cursor = min(p.cursor+1, max(len(p.found)-1, 0))
+			return p, nil
+		}
+		typed := p.input.Value()
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		if p.input.Value() != typed {
+			p.cursor = 0
+			p.filter()
+		}
+		return p, cmd
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
+
+func (p palette) View() string {
+	lines := []string{p.input.View()}
+	all := p.all()
+	// keep the cursor in the rows that are shown
+	first := max(p.cursor-paletteRows+1, 0)
+	for i := first; i < min(first+paletteRows, len(p.found)); i++ {
+		match := p.found[i]
+		line := highlight(match.Str, match.MatchedIndexes)
+		if keys := all[match.Index].keys; keys != "" {
+			line += profileHintStyle.Render("  " + keys)
+		}
+		if i == p.cursor {
+			lines = append(lines, lipgloss.NewStyle().Bold(true).Render("> ")+line)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if len(p.found) == 0 {
+		lines = append(lines, profileHintStyle.Render("  nothing matches"))
+	}
+	switch {
+	case p.loadingBoards:
+		lines = append(lines, profileHintStyle.Render("loading boards..."))
+	case p.boardsErr != nil:
+		lines = append(lines, profileErrorStyle.Width(p.width).Render("couldn't load the boards: "+p.boardsErr.Error()))
+	}
+	lines = append(lines, profileHintStyle.Render(strings.Join([]string{
+		keymap.Does("run", keymap.Keys.Enter),
+		"↑/↓: pick",
+		fmt.Sprintf("%d of %d", len(p.found), len(all)),
+		keymap.Does("cancel", keymap.Keys.Back),
+	}, " • ")))
+	return strings.Join(lines, "\n")
+}
+
+// title with the characters that matched what was typed picked out. fuzzy
+// says where they are in bytes, as ranging over a string does.
+func highlight(title string, matched []int) string {
+	var b strings.Builder
+	for i, r := range title {
+		if slices.Contains(matched, i) {
+			b.WriteString(paletteMatchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// what can be done where we are, then the config's saved queries, queries
+// that worked and the other profiles. the palette adds the boards itself.
+func (m Model) paletteEntries() []paletteEntry {
+	entries := make([]paletteEntry, 0)
+	for _, name := range viewKeys[m.router.current().state].Available() {
+		// there's nothing to retry without a failure, and the palette's
+		// already open
+		if name == "palette" || (name == "retry" && m.failure == nil) {
+			continue
+		}
+		binding, _ := keymap.Keys.Binding(name)
+		entries = append(entries, paletteEntry{title: binding.Help().Desc, keys: binding.Help().Key, msg: runCommandMsg{action: name}})
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.config.Queries)) {
+		jql := m.config.Queries[name]
+		entries = append(entries, paletteEntry{title: "saved jql: " + name, keys: jql, msg: runCommandMsg{action: "jql", args: jql}})
+	}
+	for _, jql := range m.jql.History() {
+		entries = append(entries, paletteEntry{title: "jql: " + jql, msg: runCommandMsg{action: "jql", args: jql}})
+	}
+	for _, name := range m.profiles.names {
+		if name != m.profiles.active {
+			entries = append(entries, paletteEntry{title: "switch profile: " + name, msg: runCommandMsg{action: "profiles", args: name}})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPaletteFilter(t *testing.T) {
+	entries := []paletteEntry{{title: "transition"}, {title: "log work"}, {title: "jql: project = TUI"}, {title: "switch profile: work"}}
+	tests := []struct {
+		typed  string
+		recent []string
+		want   []string
+	}{
+		{"", nil, []string{"transition", "log work", "jql: project = TUI", "switch profile: work"}},
+		// what was run lately first, most recent first
+		{"", []string{"switch profile: work", "log work"}, []string{"switch profile: work", "log work", "transition", "jql: project = TUI"}},
+		{"work", nil, []string{"log work", "switch profile: work"}},
+		{"work", []string{"switch profile: work"}, []string{"switch profile: work", "log work"}},
+		// what doesn't match isn't brought back by being recent
+		{"tui", []string{"log work"}, []string{"jql: project = TUI"}},
+		{"zzz", nil, nil},
+	}
+	for _, tt := range tests {
+		p := newPalette()
+		p.entries, p.recent = entries, tt.recent
+		p.input.SetValue(tt.typed)
+		p.filter()
+		var got []string
+		for _, match := range p.found {
+			got = append(got, match.Str)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("typing %q after running %v found %v, want %v", tt.typed, tt.recent, got, tt.want)
+		}
+	}
+}
+
+// the cursor stays on something that's there as matches drop away
+func TestPaletteCursor(t *testing.T) {
+	p := newPalette()
+	p.entries = []paletteEntry{{title: "transition"}, {title: "log work"}, {title: "comment"}}
+	p.cursor = 2
+	p.input.SetValue("log")
+	p.filter()
+	if p.cursor != 0 {
+		t.Errorf("cursor = %d with one match, want 0", p.cursor)
+	}
+}
@@ -0,0 +1,190 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+var (
+	profileHintStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	profileErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// picks which of the config's jira instances to talk to
+type profilePicker struct {
+	profiles   map[string]config.Profile
+	names      []string
+	active     string
+	cursor     int
+	connecting string // the profile being signed into, if any
+	attempt    int    // which sign in into it is being waited on
+	err        error  // why the last one couldn't be signed into
+	open       bool
+	width      int
+}
+
+// signed into another profile's jira, start over with it
+type profileConnectedMsg struct {
+	attempt  int
+	profile  string
+	jiraData jira.Backend
+}
+
+// couldn't sign into a profile's jira
+type profileFailedMsg struct {
+	attempt int
+	profile string
+	err     error
+}
+
+var lastAttempt atomic.Int64
+
+// each sign in gets an id, so that one the user stopped waiting for can be
+// told apart from the one they're waiting for now, even into the same profile
+func nextAttempt() int {
+	return int(lastAttempt.Add(1))
+}
+
+func newProfilePicker(conf config.Config) profilePicker {
+	return profilePicker{profiles: conf.Profiles, names: conf.ProfileNames(), active: conf.Profile}
+}
+
+// the config changed; the profile in use stays in use
+func (p profilePicker) reload(conf config.Config) profilePicker {
+	p.profiles, p.names = conf.Profiles, conf.ProfileNames()
+	p.cursor = min(p.cursor, len(p.names)-1)
+	return p
+}
+
+// start with the cursor on the profile in use
+func (p profilePicker) Open() profilePicker {
+	p.open, p.err, p.connecting, p.attempt = true, nil, "", 0
+	for i, name := range p.names {
+		if name == p.active {
+			p.cursor = i
+		}
+	}
+	return p
+}
+
+// switch to the profile named name
+func (p profilePicker) pick(name string) (profilePicker, tea.Cmd) {
+	i := slices.Index(p.names, name)
+	if i < 0 {
+		p.err = fmt.Errorf("there's no profile named %q", name)
+		return p, nil
+	}
+	p.cursor = i
+	// already there
+	if name == p.active {
+		p.open = false
+		return p, nil
+	}
+	var cmd tea.Cmd
+	p.connecting, p.err = name, nil
+	p.attempt, cmd = connect(name, p.profiles[name])
+	return p, cmd
+}
+
+func (p profilePicker) Focused() bool {
+	return p.open
+}
+
+// sign in off the main loop, since it's a request. what comes of it carries
+// the attempt returned.
+func connect(name string, profile config.Profile) (int, tea.Cmd) {
+	attempt := nextAttempt()
+	return attempt, func() tea.Msg {
+		if err := profile.Validate(); err != nil {
+			return profileFailedMsg{attempt: attempt, profile: name, err: err}
+		}
+		// nobody can approve an oauth login with the app in the way
+		jiraData, err := jira.NewBackend(profile, nil)
+		if err != nil {
+			return profileFailedMsg{attempt: attempt, profile: name, err: err}
+		}
+		return profileConnectedMsg{attempt: attempt, profile: name, jiraData: jiraData}
+	}
+}
+
+// whether attempt is the sign in the picker's waiting for
+func (p profilePicker) waitingFor(attempt int) bool {
+	return p.connecting != "" && attempt == p.attempt
+}
+
+func (p profilePicker) Update(msg tea.Msg) (profilePicker, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+
+	// the model only passes on sign ins someone's waiting for, so whichever
+	// it was, msg.profile is the one in use now
+	case profileConnectedMsg:
+		p.active = msg.profile
+		if p.waitingFor(msg.attempt) {
+			p.connecting, p.attempt, p.open = "", 0, false
+		}
+
+	case profileFailedMsg:
+		if p.waitingFor(msg.attempt) {
+			p.connecting, p.attempt, p.err = "", 0, msg.err
+		}
+
+	case tea.KeyMsg:
+		if p.connecting != "" {
+			if key.Matches(msg, keymap.Keys.Back) {
+				p.connecting, p.attempt, p.open = "", 0, false
+			}
+			break
+		}
+		switch {
+		case key.Matches(msg, keymap.Keys.Back):
+			p.open = false
+		case key.Matches(msg, keymap.Keys.Up):
+			p.cursor = max(p.cursor-1, 0)
+		case key.Matches(msg, keymap.Keys.Down):
+			p.cursor = min(p.cursor+1, len(p.names)-1)
+		case key.Matches(msg, keymap.Keys.Enter):
+			return p.pick(p.names[p.cursor])
+		}
+	}
+
+	return p, nil
+}
+
+func (p profilePicker) View() string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("switch profile")}
+	nameWidth := 0
+	for _, name := range p.names {
+		nameWidth = max(nameWidth, lipgloss.Width(name))
+	}
+	for i, name := range p.names {
+		line := fmt.Sprintf("%-*s  %s", nameWidth, name, profileHintStyle.Render(p.profiles[name].Url))
+		if name == p.active {
+			line += profileHintStyle.Render(" (in use)")
+		}
+		if i == p.cursor {
+			lines = append(lines, lipgloss.NewStyle().Bold(true).Render("> ")+line)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	switch {
+	case p.connecting != "":
+		lines = append(lines, profileHintStyle.Render("signing into "+p.connecting+"..."))
+	case p.err != nil:
+		lines = append(lines, profileErrorStyle.Width(p.width).Render(p.err.Error()))
+	}
+	lines = append(lines, profileHintStyle.Render(strings.Join([]string{keymap.Does("switch", keymap.Keys.Enter), keymap.Does("move", keymap.Keys.Up, keymap.Keys.Down), keymap.Does("back", keymap.Keys.Back)}, " • ")))
+	return strings.Join(lines, "\n")
+}
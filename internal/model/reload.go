@@ -0,0 +1,156 @@
+package model
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// the config file was saved, and read again. Err is why it couldn't be.
+type ConfigChangedMsg struct {
+	Config config.Config
+	Err    error
+}
+
+// the update log only says that the config changed, and whether it was any
+// good, rather than writing all of it to the log file
+func (msg ConfigChangedMsg) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("type", "ConfigChangedMsg"), slog.Any("err", msg.Err))
+}
+
+// what came of the config file changing: why the new one couldn't be used,
+// or whether to sign in again now that the profile in use says to sign in
+// differently
+type configReload struct {
+	err        error
+	profile    config.Profile // the profile in use, as the new config has it
+	asking     bool           // whether to sign in again with it
+	connecting bool
+	attempt    int // which sign in is being waited on
+	open       bool
+	width      int
+}
+
+// the new config can't be used
+func (r configReload) failed(err error) configReload {
+	return configReload{err: err, open: true, width: r.width}
+}
+
+// ask whether to sign into profile again, the way the config says to now
+func (r configReload) confirm(profile config.Profile) configReload {
+	return configReload{profile: profile, asking: true, open: true, width: r.width}
+}
+
+func (r configReload) Focused() bool {
+	return r.open
+}
+
+func (r configReload) Update(msg tea.Msg) (configReload, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		r.width = msg.Width
+
+	case profileConnectedMsg:
+		if r.waitingFor(msg.attempt) {
+			r.connecting, r.open = false, false
+		}
+
+	case profileFailedMsg:
+		if r.waitingFor(msg.attempt) {
+			r.connecting = false
+			r.err = fmt.Errorf("couldn't sign in again, so this is still signed in the old way: %w", msg.err)
+		}
+
+	case tea.KeyMsg:
+		// y signs in again, anything else keeps the old sign in
+		if r.asking {
+			r.asking = false
+			if msg.String() != "y" {
+				r.open = false
+				return r, nil
+			}
+			var cmd tea.Cmd
+			r.connecting = true
+			r.attempt, cmd = connect(r.profile.Name, r.profile)
+			return r, cmd
+		}
+		if key.Matches(msg, keymap.Keys.Back) || (!r.connecting && key.Matches(msg, keymap.Keys.Enter)) {
+			r.connecting, r.open = false, false
+		}
+	}
+
+	return r, nil
+}
+
+// whether attempt is the sign in the reload's waiting for
+func (r configReload) waitingFor(attempt int) bool {
+	return r.connecting && attempt == r.attempt
+}
+
+func (r configReload) View() string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("config changed")}
+	switch {
+	case r.asking:
+		lines = append(lines, fmt.Sprintf("%s signs in differently now. sign in again, going back to the boards? (y/n)", r.profile.Name))
+	case r.connecting:
+		lines = append(lines, profileHintStyle.Render("signing into "+r.profile.Name+" again..."), profileHintStyle.Render(keymap.Does("stop waiting", keymap.Keys.Back)))
+	case r.err != nil:
+		lines = append(lines, profileErrorStyle.Width(r.width).Render(r.err.Error()), profileHintStyle.Render(keymap.Does("dismiss", keymap.Keys.Back)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// use what the config says now, staying on the profile in use. if that
+// signs in differently now, it's up to the user whether to sign in again,
+// since that means starting over from the boards.
+func (m Model) reloadConfig(msg ConfigChangedMsg) (Model, tea.Cmd) {
+	conf, err := msg.Config, msg.Err
+	if err == nil {
+		conf.Profile = m.config.Profile
+		if _, ok := conf.Profiles[conf.Profile]; !ok {
+			err = fmt.Errorf("there's no profile named %q anymore", conf.Profile)
+		} else if problems := conf.ValidateInUse(); len(problems) > 0 {
+			err = problems
+		}
+	}
+	if err != nil {
+		m.reload = m.reload.failed(fmt.Errorf("kept the config from before: %w", err))
+		return m, nil
+	}
+
+	// whatever was wrong with it before has been fixed
+	if m.reload.err != nil && !m.reload.connecting {
+		m.reload.open = false
+	}
+	signedIn := m.config.Active()
+	m.config = conf
+	m.profiles = m.profiles.reload(conf)
+	cmds := make([]tea.Cmd, 0)
+	var cmd tea.Cmd
+	if keys := conf.KeyMap(); !reflect.DeepEqual(keys, keymap.Keys) {
+		keymap.Keys = keys
+		m, cmd = m.updateViews(jira.KeysChangedMsg{})
+		cmds = append(cmds, cmd)
+	}
+	if conf.AccentColor != m.AccentColor {
+		m.AccentColor = conf.AccentColor
+		m.statusBar = newStatusBar(m.AccentColor)
+		m.statusBar.SetSize(m.globalWidth)
+		m, cmd = m.updateViews(jira.AccentColorMsg{Color: m.AccentColor})
+		cmds = append(cmds, cmd)
+	}
+	if !reflect.DeepEqual(signedIn, conf.Active()) {
+		m.reload = m.reload.confirm(conf.Active())
+	}
+	m.refreshStatusBar()
+	return m, tea.Batch(cmds...)
+}
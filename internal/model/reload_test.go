@@ -0,0 +1,122 @@
+package model
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guppy0130/go-jira-tui/internal/config"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// every save of the config file goes through the update log, which mustn't
+// end up holding the token
+func TestConfigChangedLog(t *testing.T) {
+	msg := ConfigChangedMsg{
+		Config: config.Config{Token: "top-token", Profiles: map[string]config.Profile{"work": {Token: "work-token"}}},
+		Err:    errors.New("url: needs a url"),
+	}
+	var b strings.Builder
+	slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug})).Debug("update", "msg", msg)
+	logged := b.String()
+	for _, secret := range []string{"top-token", "work-token"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("logged %q: %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, "needs a url") {
+		t.Errorf("logged without why the config couldn't be used: %s", logged)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	fake, err := jira.NewFakeBackend(jira.Fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := func(change func(*config.Config)) config.Config {
+		c := config.Config{
+			LogFormat:   "json",
+			AccentColor: "57",
+			Profiles: map[string]config.Profile{
+				"work": {Name: "work", Url: "fake://"},
+				"home": {Name: "home", Url: "fake://?latency=1s"},
+			},
+			Profile: "work",
+		}
+		if change != nil {
+			change(&c)
+		}
+		return c
+	}
+	tests := []struct {
+		name   string
+		msg    ConfigChangedMsg
+		kept   bool   // whether the old config's still in use
+		err    string // part of why
+		asking bool   // whether it asks to sign in again
+		accent lipgloss.Color
+		preset string // key_preset in use after
+	}{
+		{
+			name: "couldn't be read",
+			msg:  ConfigChangedMsg{Err: errors.New("bad config file? yaml: line 3")},
+			kept: true, err: "line 3", accent: "57",
+		},
+		{
+			name: "the profile in use is gone",
+			msg:  ConfigChangedMsg{Config: conf(func(c *config.Config) { delete(c.Profiles, "work"); c.Profile = "home" })},
+			kept: true, err: `no profile named "work"`, accent: "57",
+		},
+		{
+			name: "the profile in use is broken",
+			msg:  ConfigChangedMsg{Config: conf(func(c *config.Config) { c.Profiles["work"] = config.Profile{Name: "work", Url: "not a url"} })},
+			kept: true, err: "isn't a url", accent: "57",
+		},
+		{
+			name:   "only the look changed",
+			msg:    ConfigChangedMsg{Config: conf(func(c *config.Config) { c.AccentColor = "99"; c.KeyPreset = "vim" })},
+			accent: "99", preset: "vim",
+		},
+		{
+			name:   "stays on the profile in use",
+			msg:    ConfigChangedMsg{Config: conf(func(c *config.Config) { c.Profile = "home"; c.AccentColor = "99" })},
+			accent: "99",
+		},
+		{
+			name:   "the profile in use signs in differently",
+			msg:    ConfigChangedMsg{Config: conf(func(c *config.Config) { c.Profiles["work"] = config.Profile{Name: "work", Url: "fake://?pagesize=2"} })},
+			asking: true, accent: "57",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePreset(t, "default")
+			before := conf(nil)
+			m, _ := NewModel(fake, before).reloadConfig(tt.msg)
+
+			if kept := reflect.DeepEqual(m.config, before); kept != tt.kept {
+				t.Errorf("kept the old config: %v, want %v", kept, tt.kept)
+			}
+			if m.config.Profile != "work" {
+				t.Errorf("using %s, want work still", m.config.Profile)
+			}
+			if (m.reload.err == nil) != (tt.err == "") || (m.reload.err != nil && !strings.Contains(m.reload.err.Error(), tt.err)) {
+				t.Errorf("reload err = %v, want %q", m.reload.err, tt.err)
+			}
+			if m.reload.asking != tt.asking || m.reload.open != (tt.asking || tt.err != "") {
+				t.Errorf("reload asking, open = %v, %v, want %v", m.reload.asking, m.reload.open, tt.asking)
+			}
+			if m.AccentColor != tt.accent {
+				t.Errorf("AccentColor = %s, want %s", m.AccentColor, tt.accent)
+			}
+			if want := conf(func(c *config.Config) { c.KeyPreset = tt.preset }).KeyMap(); !reflect.DeepEqual(keymap.Keys, want) {
+				t.Errorf("keys aren't the %q preset's", tt.preset)
+			}
+		})
+	}
+}
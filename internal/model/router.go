@@ -0,0 +1,96 @@
+package model
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/jira"
+)
+
+// one view in the navigation stack
+type route struct {
+	state ViewState
+	view  tea.Model
+}
+
+// views that know what they're showing, e.g. a board's name or an issue key
+type titled interface {
+	Title() string
+}
+
+func (r route) title() string {
+	if t, ok := r.view.(titled); ok {
+		return t.Title()
+	}
+	return string(r.state)
+}
+
+// the stack of views we've drilled through, e.g. boards → sprints → issues
+// → issue. the last one is showing. the views underneath are kept as they
+// were, so going back restores their cursor, filter and scroll position
+// without refetching anything.
+type router struct {
+	stack []route
+}
+
+func newRouter(state ViewState, view tea.Model) router {
+	return router{stack: []route{{state: state, view: view}}}
+}
+
+func (r router) current() route {
+	return r.stack[len(r.stack)-1]
+}
+
+func (r *router) push(state ViewState, view tea.Model) {
+	r.stack = append(r.stack, route{state: state, view: view})
+}
+
+// drop the current view. the root view is never popped.
+func (r *router) pop() bool {
+	if len(r.stack) <= 1 {
+		return false
+	}
+	r.stack = r.stack[:len(r.stack)-1]
+	return true
+}
+
+// how we got here, e.g. "boards > TUI > TUI Sprint 2 > TUI-2"
+func (r router) breadcrumbs() string {
+	crumbs := make([]string, 0, len(r.stack))
+	for _, route := range r.stack {
+		crumbs = append(crumbs, route.title())
+	}
+	return strings.Join(crumbs, " > ")
+}
+
+// do the action named action in the view that's showing, if it does actions
+// by name and that's one of them
+func (r *router) do(action string) (tea.Cmd, bool) {
+	top := len(r.stack) - 1
+	doer, ok := r.stack[top].view.(jira.Doer)
+	if !ok {
+		return nil, false
+	}
+	view, cmd, done := doer.Do(action)
+	r.stack[top].view = view
+	return cmd, done
+}
+
+// keystrokes only go to the view that's showing. everything else goes to
+// every view, since pages may still be arriving for views underneath.
+func (r *router) update(msg tea.Msg) tea.Cmd {
+	if _, isKey := msg.(tea.KeyMsg); isKey {
+		top := len(r.stack) - 1
+		view, cmd := r.stack[top].view.Update(msg)
+		r.stack[top].view = view
+		return cmd
+	}
+
+	cmds := make([]tea.Cmd, 0, len(r.stack))
+	for i := range r.stack {
+		view, cmd := r.stack[i].view.Update(msg)
+		r.stack[i].view = view
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guppy0130/go-jira-tui/internal/keymap"
+)
+
+// a view that counts what it's sent
+type countingView struct {
+	title string
+	keys  int
+	other int
+}
+
+func (v countingView) Init() tea.Cmd { return nil }
+func (v countingView) View() string  { return v.title }
+func (v countingView) Title() string { return v.title }
+
+func (v countingView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		v.keys++
+	} else {
+		v.other++
+	}
+	return v, nil
+}
+
+func TestRouter(t *testing.T) {
+	r := newRouter(ViewStateBoards, countingView{title: "boards"})
+	if r.pop() {
+		t.Error("popped the boards, which are always there")
+	}
+	r.push(ViewStateSprints, countingView{title: "TUI"})
+	r.push(ViewStateIssues, countingView{title: "TUI Sprint 2"})
+	if got := r.breadcrumbs(); got != "boards > TUI > TUI Sprint 2" {
+		t.Errorf("breadcrumbs() = %q", got)
+	}
+
+	// keys are for the view that's showing, everything else for all of them
+	r.update(keymap.Press("j"))
+	r.update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	want := []countingView{{"boards", 0, 1}, {"TUI", 0, 1}, {"TUI Sprint 2", 1, 1}}
+	for i, route := range r.stack {
+		if got := route.view.(countingView); got != want[i] {
+			t.Errorf("%s got %d keys and %d other msgs, want %d and %d", got.title, got.keys, got.other, want[i].keys, want[i].other)
+		}
+	}
+
+	// going back finds the view as it was
+	if !r.pop() || r.current().state != ViewStateSprints || r.current().view.(countingView) != want[1] {
+		t.Errorf("went back to %v, want %v", r.current(), want[1])
+	}
+	if _, done := r.do("top"); done {
+		t.Error("a view that doesn't do actions by name did one")
+	}
+}
@@ -29,15 +29,15 @@ func NewStructuredBubbleTeaLogger(loggerFormat LoggerFormat) StructuredBubbleTea
 }
 
 func (l StructuredBubbleTeaLogger) SetOutput(w io.Writer) {
-	l.logger = *slog.New(
-		slog.NewTextHandler(
-			w,
-			&slog.HandlerOptions{
-				AddSource: true,
-				Level:     slog.LevelDebug,
-			},
-		),
-	)
+	options := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelDebug,
+	}
+	var handler slog.Handler = slog.NewJSONHandler(w, options)
+	if l.format == LoggerFormatText {
+		handler = slog.NewTextHandler(w, options)
+	}
+	l.logger = *slog.New(handler)
 	slog.SetDefault(&l.logger)
 }
 
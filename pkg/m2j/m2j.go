@@ -0,0 +1,168 @@
+// the other way around from github.com/guppy0130/j2m: github flavored
+// markdown in, jira wiki markup out
+package m2j
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type conversion struct {
+	re   *regexp.Regexp
+	repl any // a replacement string, or a func from submatches to one
+}
+
+var (
+	// code doesn't get converted, so it's swapped out for placeholders
+	// first and put back last
+	codeBlockRe  = regexp.MustCompile("(?m)^```[ \t]*([A-Za-z0-9_+-]*)[ \t]*\n([\\s\\S]*?)\n?^```[ \t]*$")
+	inlineCodeRe = regexp.MustCompile("`([^`\n]+)`")
+	placeholder  = regexp.MustCompile("\x00(\\d+)\x00")
+
+	// a table's header is the line above its |---|---| separator
+	tableHeaderRe = regexp.MustCompile(`(?m)^[ \t]*(\|.*\|)[ \t]*\n[ \t]*\|(?:[ \t]*:?-+:?[ \t]*\|)+[ \t]*$`)
+)
+
+var conversions = []conversion{
+	{ // headers 1-6. a closing run of #s needs a space before it, so C# keeps
+		// its #
+		re: regexp.MustCompile(`(?m)^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`),
+		repl: func(groups []string) string {
+			return fmt.Sprintf("h%d. %s", len(groups[1]), groups[2])
+		},
+	},
+	{ // horizontal rules, before the lists that also start with - or *
+		re:   regexp.MustCompile(`(?m)^[ \t]*([-*_])(?:[ \t]*([-*_])){2,}[ \t]*$`),
+		repl: "----",
+	},
+	{ // bold italics as *_both_*; bold and then italics on their own would
+		// cross them over
+		re:   regexp.MustCompile(`(\*\*\*|___)(\S(?:.*?\S)?)(\*\*\*|___)`),
+		repl: "\x01_${2}_\x01",
+	},
+	{ // bold, held back as \x01 so italics don't see it
+		re:   regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`),
+		repl: "\x01$2\x01",
+	},
+	{ // italics
+		re:   regexp.MustCompile(`\*(\S(?:[^*\n]*?\S)?)\*`),
+		repl: "_${1}_",
+	},
+	{ // ... and bold again
+		re:   regexp.MustCompile("\x01"),
+		repl: "*",
+	},
+	{ // strikethrough
+		re:   regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`),
+		repl: "-$1-",
+	},
+	{ // the html j2m makes for things markdown can't do
+		re:   regexp.MustCompile(`<ins>(.*?)</ins>`),
+		repl: "+$1+",
+	},
+	{
+		re:   regexp.MustCompile(`<sup>(.*?)</sup>`),
+		repl: "^$1^",
+	},
+	{
+		re:   regexp.MustCompile(`<sub>(.*?)</sub>`),
+		repl: "~$1~",
+	},
+	{
+		re:   regexp.MustCompile(`<cite>(.*?)</cite>`),
+		repl: "??$1??",
+	},
+	{ // images, before links since they look the same but for the !
+		re:   regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)(?:\s+"[^"]*")?\)`),
+		repl: "!$1!",
+	},
+	{ // named links
+		re:   regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`),
+		repl: "[$1|$2]",
+	},
+	{ // bare links
+		re:   regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`),
+		repl: "[$1]",
+	},
+	{ // single paragraph blockquotes
+		re:   regexp.MustCompile(`(?m)^>[ \t]?`),
+		repl: "bq. ",
+	},
+	{ // unordered lists, two spaces of indent a level
+		re: regexp.MustCompile(`(?m)^([ \t]*)[*+-][ \t]+`),
+		repl: func(groups []string) string {
+			return strings.Repeat("*", listDepth(groups[1])) + " "
+		},
+	},
+	{ // ordered lists
+		re: regexp.MustCompile(`(?m)^([ \t]*)\d+[.)][ \t]+`),
+		repl: func(groups []string) string {
+			return strings.Repeat("#", listDepth(groups[1])) + " "
+		},
+	},
+}
+
+// MDToJira takes github flavored markdown and returns jira wiki markup
+func MDToJira(str string) string {
+	code := make([]string, 0)
+	hold := func(s string) string {
+		code = append(code, s)
+		return fmt.Sprintf("\x00%d\x00", len(code)-1)
+	}
+
+	str = replaceAllStringSubmatchFunc(codeBlockRe, str, func(groups []string) string {
+		if groups[1] == "" {
+			return hold("{code}\n" + groups[2] + "\n{code}")
+		}
+		return hold(fmt.Sprintf("{code:%s}\n%s\n{code}", groups[1], groups[2]))
+	})
+	str = replaceAllStringSubmatchFunc(inlineCodeRe, str, func(groups []string) string {
+		return hold("{{" + groups[1] + "}}")
+	})
+
+	str = replaceAllStringSubmatchFunc(tableHeaderRe, str, func(groups []string) string {
+		return strings.ReplaceAll(groups[1], "|", "||")
+	})
+	for _, c := range conversions {
+		switch repl := c.repl.(type) {
+		case string:
+			str = c.re.ReplaceAllString(str, repl)
+		case func([]string) string:
+			str = replaceAllStringSubmatchFunc(c.re, str, repl)
+		}
+	}
+
+	return replaceAllStringSubmatchFunc(placeholder, str, func(groups []string) string {
+		i, _ := strconv.Atoi(groups[1])
+		return code[i]
+	})
+}
+
+// how deep a list item is nested, going by its indent
+func listDepth(indent string) int {
+	indent = strings.ReplaceAll(indent, "\t", "  ")
+	return len(indent)/2 + 1
+}
+
+// like regexp's ReplaceAllStringFunc, but with the submatches
+func replaceAllStringSubmatchFunc(re *regexp.Regexp, str string, repl func([]string) string) string {
+	result := strings.Builder{}
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(str, -1) {
+		groups := make([]string, 0, len(match)/2)
+		for i := 0; i < len(match); i += 2 {
+			if match[i] < 0 {
+				groups = append(groups, "")
+				continue
+			}
+			groups = append(groups, str[match[i]:match[i+1]])
+		}
+		result.WriteString(str[last:match[0]])
+		result.WriteString(repl(groups))
+		last = match[1]
+	}
+	result.WriteString(str[last:])
+	return result.String()
+}
@@ -0,0 +1,56 @@
+package m2j
+
+import "testing"
+
+func TestMDToJira(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"heading", "# Title", "h1. Title"},
+		{"deepest heading", "###### Title", "h6. Title"},
+		{"closing hashes", "## Title ##", "h2. Title"},
+		{"hash in the title", "# C#", "h1. C#"},
+		{"hash in the title with closing hashes", "# C# #", "h1. C#"},
+		{"too deep for a heading", "####### Title", "####### Title"},
+		{"no space isn't a heading", "#hashtag", "#hashtag"},
+
+		{"bold", "**bold**", "*bold*"},
+		{"bold underscores", "__bold__", "*bold*"},
+		{"italics", "*italic*", "_italic_"},
+		{"bold italics", "***bold italic***", "*_bold italic_*"},
+		{"bold italics underscores", "___bold italic___", "*_bold italic_*"},
+		{"italics in bold", "**bold *and italic* too**", "*bold _and italic_ too*"},
+		{"bold in italics", "*italic **and bold** too*", "_italic *and bold* too_"},
+		{"bold then italics", "**bold** and *italic*", "*bold* and _italic_"},
+		{"strikethrough", "~~gone~~", "-gone-"},
+		{"lone asterisks", "2 * 3 * 4", "2 * 3 * 4"},
+
+		{"unordered list", "- one\n- two", "* one\n* two"},
+		{"nested list", "- one\n  - two\n    - three", "* one\n** two\n*** three"},
+		{"ordered list", "1. one\n2. two", "# one\n# two"},
+		{"nested ordered list", "1. one\n   1. two", "# one\n## two"},
+		{"horizontal rule", "---", "----"},
+
+		{"inline code", "run `go **build**`", "run {{go **build**}}"},
+		{"code block", "```\n# not a heading\n```", "{code}\n# not a heading\n{code}"},
+		{"code block with a language", "```go\nx := *y\n```", "{code:go}\nx := *y\n{code}"},
+
+		{"table", "| a | b |\n|---|:-:|\n| 1 | 2 |", "|| a || b ||\n| 1 | 2 |"},
+
+		{"quote", "> quoted", "bq. quoted"},
+		{"quote without a space", ">quoted", "bq. quoted"},
+
+		{"link", "[docs](https://example.com)", "[docs|https://example.com]"},
+		{"bare link", "<https://example.com>", "[https://example.com]"},
+		{"image", "![alt](https://example.com/a.png)", "!https://example.com/a.png!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MDToJira(tt.md); got != tt.want {
+				t.Errorf("MDToJira(%q) = %q, want %q", tt.md, got, tt.want)
+			}
+		})
+	}
+}